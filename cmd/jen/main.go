@@ -1,11 +1,14 @@
 package main
 
 import (
+	"errors"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/generator"
 	flag "github.com/spf13/pflag"
 )
 
@@ -34,6 +37,9 @@ const defaultGoFile = "model_infra.go"
 // 支持的命令行参数：
 //
 //	-c, --config: 指定配置文件路径（可选）
+//	-a, --artifacts: 只生成指定的产物，逗号分隔，覆盖配置文件中的 artifacts（可选）
+//	--dry-run: 只打印生成的代码与已有文件的 diff，不写入文件
+//	--check: 生成的代码与已有文件不一致时以非零状态码退出，不写入文件
 //	-v, --version: 显示版本号
 //
 // 使用示例：
@@ -41,11 +47,18 @@ const defaultGoFile = "model_infra.go"
 //	jen                                    # 自动选择最合适的方式
 //	jen -c ./my-config.yml                 # 强制使用指定的配置文件（最高优先级）
 //	jen --config /path/to/config.yml       # 使用长格式参数
+//	jen -a po,dao,tool/ptr                 # 只生成 PO、DAO 和 tool/ptr.go
+//	jen --dry-run > changes.patch          # 预览将要修改的代码
+//	jen --check                            # 在 CI 中检查提交的代码是否与表结构一致
 //	jen -v                                 # 显示版本号
 //	jen --version                          # 显示版本号（长格式）
 func main() {
 	// 定义命令行参数
 	configPath := flag.StringP("config", "c", "", "配置文件路径")
+	var options runOptions
+	flag.StringSliceVarP(&options.artifacts, "artifacts", "a", nil, "只生成指定的产物，逗号分隔（如 po,dao,tool/ptr），覆盖配置文件中的 artifacts")
+	flag.BoolVar(&options.dryRun, "dry-run", false, "只打印生成的代码与已有文件的 diff，不写入文件")
+	flag.BoolVar(&options.check, "check", false, "生成的代码与已有文件不一致时以非零状态码退出，不写入文件")
 	showVersion := flag.BoolP("version", "v", false, "显示版本号")
 	flag.Parse()
 
@@ -58,7 +71,7 @@ func main() {
 	// 优先级 1: 用户指定的配置文件（最高优先级，用户意图优先）
 	if *configPath != "" {
 		log.Printf("📋 使用用户指定的配置文件: %s", *configPath)
-		if err := runWithConfig(*configPath); err != nil {
+		if err := runWithConfig(*configPath, options); err != nil {
 			log.Fatalf("❌ 使用配置文件 %s 失败: %v", *configPath, err)
 		}
 		log.Println("🎊 程序执行完成")
@@ -68,6 +81,9 @@ func main() {
 	// 优先级 2: 检查是否存在 model_infra.go 文件
 	if fileExists(defaultGoFile) {
 		log.Printf("🎯 检测到 %s 文件，直接执行...", defaultGoFile)
+		if options.isSet() {
+			log.Printf("⚠️ --artifacts、--dry-run、--check 只在使用配置文件时生效，请在 %s 中通过 Builder 的 Artifacts()、DryRun()、Check() 配置", defaultGoFile)
+		}
 		if err := runGoFile(defaultGoFile); err != nil {
 			log.Fatalf("❌ 执行 %s 失败: %v", defaultGoFile, err)
 		}
@@ -80,7 +96,11 @@ func main() {
 	for _, path := range defaultConfigPaths {
 		if fileExists(path) {
 			log.Printf("📁 找到配置文件: %s", path)
-			if err := runWithConfig(path); err != nil {
+			if err := runWithConfig(path, options); err != nil {
+				// check 不通过说明配置可用但代码已过期，不再尝试其他配置文件
+				if errors.Is(err, generator.ErrStaleFiles) {
+					log.Fatalf("❌ %v", err)
+				}
 				log.Printf("⚠️ 配置文件 %s 加载失败: %v，继续尝试下一个...", path, err)
 				continue
 			}
@@ -128,6 +148,27 @@ func main() {
 	os.Exit(1)
 }
 
+// runOptions 使用配置文件运行时可以覆盖配置的命令行参数
+type runOptions struct {
+	artifacts []string // --artifacts，只生成指定的产物
+	dryRun    bool     // --dry-run，只打印 diff，不写入文件
+	check     bool     // --check，代码过期时返回错误，不写入文件
+}
+
+// isSet 是否指定了任一参数
+func (o runOptions) isSet() bool {
+	return len(o.artifacts) > 0 || o.dryRun || o.check
+}
+
+// apply 将命令行参数写入配置，未指定的参数保留配置文件中的值
+func (o runOptions) apply(cfg *config.Configger) {
+	if len(o.artifacts) > 0 {
+		cfg.GenerateOption.Artifacts = o.artifacts
+	}
+	cfg.GenerateOption.DryRun = o.dryRun
+	cfg.GenerateOption.Check = o.check
+}
+
 // fileExists 检查文件是否存在
 // 参数:
 //
@@ -178,11 +219,12 @@ func runGoFile(filename string) error {
 // 参数:
 //
 //	configPath: 配置文件路径
+//	options: 命令行参数，覆盖配置文件中的对应配置
 //
 // 返回:
 //
 //	error: 执行过程中的错误，nil 表示成功
-func runWithConfig(configPath string) error {
+func runWithConfig(configPath string, options runOptions) error {
 	log.Println("🚀 开始执行代码生成...")
 
 	// 初始化应用实例
@@ -191,6 +233,9 @@ func runWithConfig(configPath string) error {
 		return err
 	}
 
+	// 命令行参数优先于配置文件，生成器与应用共用同一个配置对象
+	options.apply(appInstance.Config)
+
 	// 运行应用
 	if err = appInstance.Run(); err != nil {
 		return err
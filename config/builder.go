@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/LingoJack/model_infrax/tool"
 )
@@ -27,7 +28,7 @@ func NewBuilder() *ConfiggerBuilder {
 		config: &Configger{
 			GenerateConfig: GenerateConfig{
 				GenerateMode: "database", // 默认从数据库生成
-				URLTemplate:  "mysql://%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+				Dialect:      "mysql",    // 默认使用 MySQL
 				AllTables:    false,
 				TableNames:   []string{},
 			},
@@ -66,21 +67,91 @@ func (b *ConfiggerBuilder) DatabaseMode(host string, port int, dbName, username,
 	return b
 }
 
+// Dialect 配置数据库方言（仅 database 模式有效）
+// dialect: 方言名称，支持 "mysql"（默认）、"postgres" 和 "clickhouse"
+func (b *ConfiggerBuilder) Dialect(dialect string) *ConfiggerBuilder {
+	b.config.GenerateConfig.Dialect = dialect
+	return b
+}
+
+// SchemaName 配置 postgres 的 schema 名称
+// schemaName: schema 名称，为空时使用 public
+func (b *ConfiggerBuilder) SchemaName(schemaName string) *ConfiggerBuilder {
+	b.config.GenerateConfig.SchemaName = schemaName
+	return b
+}
+
 // StatementMode 配置从SQL文件生成模式
 // sqlFilePath: SQL文件路径，支持 ~ 符号表示用户目录
+// 也可以是 goose、golang-migrate、Flyway 的迁移目录或 glob，按版本号顺序执行 up 部分
 func (b *ConfiggerBuilder) StatementMode(sqlFilePath string) *ConfiggerBuilder {
 	b.config.GenerateConfig.GenerateMode = "statement"
 	b.config.GenerateConfig.SqlFilePath = tool.EscapeHomeDir(sqlFilePath)
 	return b
 }
 
+// SqliteMode 配置从SQLite文件生成模式
+// sqliteFilePath: SQLite数据库文件路径，支持 ~ 符号表示用户目录
+func (b *ConfiggerBuilder) SqliteMode(sqliteFilePath string) *ConfiggerBuilder {
+	b.config.GenerateConfig.GenerateMode = "sqlite"
+	b.config.GenerateConfig.SqliteFilePath = tool.EscapeHomeDir(sqliteFilePath)
+	return b
+}
+
 // URLTemplate 自定义数据库连接URL模板
-// template: URL模板字符串，例如: "mysql://%s:%s@tcp(%s:%d)/%s?charset=utf8mb4"
+// template: URL模板字符串，按 用户名、密码、主机、端口、数据库名 的顺序填充，例如: "%s:%s@tcp(%s:%d)/%s?charset=utf8mb4"
 func (b *ConfiggerBuilder) URLTemplate(template string) *ConfiggerBuilder {
 	b.config.GenerateConfig.URLTemplate = template
 	return b
 }
 
+// DSN 配置完整的数据库连接串
+// dsn: 原样交给数据库驱动的连接串，配置后忽略 URLTemplate 和其他连接配置
+func (b *ConfiggerBuilder) DSN(dsn string) *ConfiggerBuilder {
+	b.config.GenerateConfig.DSN = dsn
+	return b
+}
+
+// PasswordFromEnv 配置从环境变量读取数据库密码
+// envName: 环境变量名，例如 "MYSQL_PASSWORD"
+func (b *ConfiggerBuilder) PasswordFromEnv(envName string) *ConfiggerBuilder {
+	b.config.GenerateConfig.Password = ""
+	b.config.GenerateConfig.PasswordEnv = envName
+	return b
+}
+
+// PasswordFromFile 配置从文件读取数据库密码
+// path: 密码文件路径，支持 ~ 符号表示用户目录，文件内容的首尾空白会被去除
+func (b *ConfiggerBuilder) PasswordFromFile(path string) *ConfiggerBuilder {
+	b.config.GenerateConfig.Password = ""
+	b.config.GenerateConfig.PasswordFile = tool.EscapeHomeDir(path)
+	return b
+}
+
+// Socket 配置通过 unix socket 连接数据库（mysql、postgres 有效）
+// path: mysql 为 socket 文件路径，postgres 为 socket 所在目录
+func (b *ConfiggerBuilder) Socket(path string) *ConfiggerBuilder {
+	b.config.GenerateConfig.Socket = tool.EscapeHomeDir(path)
+	return b
+}
+
+// ConnectTimeout 配置连接超时时间
+// timeout: 超时时间，为 0 时使用驱动默认值
+func (b *ConfiggerBuilder) ConnectTimeout(timeout time.Duration) *ConfiggerBuilder {
+	b.config.GenerateConfig.ConnectTimeout = timeout
+	return b
+}
+
+// TLS 配置数据库连接的 TLS 参数
+// tlsConfig: TLS 配置，Mode 支持 disable、require、verify-ca、verify-full
+func (b *ConfiggerBuilder) TLS(tlsConfig TLSConfig) *ConfiggerBuilder {
+	tlsConfig.CAFile = tool.EscapeHomeDir(tlsConfig.CAFile)
+	tlsConfig.CertFile = tool.EscapeHomeDir(tlsConfig.CertFile)
+	tlsConfig.KeyFile = tool.EscapeHomeDir(tlsConfig.KeyFile)
+	b.config.GenerateConfig.TLS = tlsConfig
+	return b
+}
+
 // AllTables 配置生成所有表
 // 如果设置为true，将忽略 Tables() 方法设置的表名列表
 func (b *ConfiggerBuilder) AllTables() *ConfiggerBuilder {
@@ -97,6 +168,20 @@ func (b *ConfiggerBuilder) Tables(tableNames ...string) *ConfiggerBuilder {
 	return b
 }
 
+// IncludeTables 配置需要生成的表名模式，可以与 Tables() 同时使用
+// patterns: 支持 glob（如 "t_order_*"），以 "re:" 开头时为正则表达式（如 "re:^t_(user|order)$"）
+func (b *ConfiggerBuilder) IncludeTables(patterns ...string) *ConfiggerBuilder {
+	b.config.GenerateConfig.IncludeTables = patterns
+	return b
+}
+
+// ExcludeTables 配置需要跳过的表名模式，优先级高于 AllTables()、Tables()、IncludeTables()
+// patterns: 规则同 IncludeTables，如 "*_bak"、"*_tmp"
+func (b *ConfiggerBuilder) ExcludeTables(patterns ...string) *ConfiggerBuilder {
+	b.config.GenerateConfig.ExcludeTables = patterns
+	return b
+}
+
 // OutputPath 配置代码输出路径
 // path: 输出路径，支持 ~ 符号表示用户目录
 func (b *ConfiggerBuilder) OutputPath(path string) *ConfiggerBuilder {
@@ -104,20 +189,75 @@ func (b *ConfiggerBuilder) OutputPath(path string) *ConfiggerBuilder {
 	return b
 }
 
+// ModulePath 配置 output_path 对应的 Go 导入路径（如 "github.com/foo/app/internal/model"）
+// 生成的 DTO、VO、DAO 以此拼接 package_name 导入 PO、DTO 包，未配置时从 output_path 向上查找 go.mod 推导
+func (b *ConfiggerBuilder) ModulePath(modulePath string) *ConfiggerBuilder {
+	b.config.GenerateOption.ModulePath = modulePath
+	return b
+}
+
 // IgnoreTableNamePrefix 配置是否忽略表名前缀
-// 如果设置为true，生成的类名将去除表名前缀（如 t_user -> User）
+// 如果设置为true，生成的类名、文件名将去除表名前缀（如 t_user -> User），TableName() 仍返回真实表名
+// 未通过 TableNamePrefixes 等方法配置规则时，默认去除 t_、tb_、tbl_ 前缀
 func (b *ConfiggerBuilder) IgnoreTableNamePrefix(ignore bool) *ConfiggerBuilder {
 	b.config.GenerateOption.IgnoreTableNamePrefix = ignore
 	return b
 }
 
-// CrudOnlyIdx 配置是否仅对索引字段生成CRUD方法
-// 如果设置为true，只会为有索引的字段生成查询方法
+// TableNamePrefixes 配置生成类型名、文件名时去除的表名前缀，同时开启 IgnoreTableNamePrefix
+// 按顺序匹配，只去除第一个匹配的前缀（如 "t_", "tb_"）
+func (b *ConfiggerBuilder) TableNamePrefixes(prefixes ...string) *ConfiggerBuilder {
+	b.config.GenerateOption.IgnoreTableNamePrefix = true
+	b.config.GenerateOption.TableNameTrim.Prefixes = prefixes
+	return b
+}
+
+// TableNameSuffixes 配置生成类型名、文件名时去除的表名后缀，同时开启 IgnoreTableNamePrefix
+// 按顺序匹配，只去除第一个匹配的后缀（如 "_tab"）
+func (b *ConfiggerBuilder) TableNameSuffixes(suffixes ...string) *ConfiggerBuilder {
+	b.config.GenerateOption.IgnoreTableNamePrefix = true
+	b.config.GenerateOption.TableNameTrim.Suffixes = suffixes
+	return b
+}
+
+// TableNameRegexps 配置生成类型名、文件名时从表名中去除的正则表达式，同时开启 IgnoreTableNamePrefix
+// 匹配的部分被替换为空（如 "^app\\d+_" 将 app01_user 转换为 user）
+func (b *ConfiggerBuilder) TableNameRegexps(patterns ...string) *ConfiggerBuilder {
+	b.config.GenerateOption.IgnoreTableNamePrefix = true
+	b.config.GenerateOption.TableNameTrim.Regexps = patterns
+	return b
+}
+
+// CrudOnlyIdx 配置是否仅对索引字段生成查询条件
+// 如果设置为true，DTO 和 build<Entity>QueryCondition 只包含索引列（精确、IN、范围条件），并且不生成模糊查询
 func (b *ConfiggerBuilder) CrudOnlyIdx(onlyIdx bool) *ConfiggerBuilder {
 	b.config.GenerateOption.CrudOnlyIdx = onlyIdx
 	return b
 }
 
+// DbTypeOverride 配置按数据库类型覆盖 Go 类型，可以多次调用，按调用顺序匹配
+// dbType: 数据库类型，支持 glob（如 "decimal"、"tinyint(1)"）
+// goType: Go 类型，需要导入时写完整的导入路径（如 "github.com/shopspring/decimal.Decimal"），可空列使用其指针类型
+func (b *ConfiggerBuilder) DbTypeOverride(dbType, goType string) *ConfiggerBuilder {
+	b.config.GenerateOption.TypeOverrides = append(b.config.GenerateOption.TypeOverrides, TypeOverride{DbType: dbType, GoType: goType})
+	return b
+}
+
+// ColumnTypeOverride 配置按列覆盖 Go 类型，优先于 DbTypeOverride
+// column: 表名.列名，支持 glob（如 "t_order.extra"、"*.amount"）
+// goType: 规则同 DbTypeOverride
+func (b *ConfiggerBuilder) ColumnTypeOverride(column, goType string) *ConfiggerBuilder {
+	b.config.GenerateOption.TypeOverrides = append(b.config.GenerateOption.TypeOverrides, TypeOverride{Column: column, GoType: goType})
+	return b
+}
+
+// NullableStyle 配置可空列的 Go 类型风格
+// style: pointer（默认，*string）、sql_null（sql.NullString）或 generic（sql.Null[string]）
+func (b *ConfiggerBuilder) NullableStyle(style string) *ConfiggerBuilder {
+	b.config.GenerateOption.NullableStyle = style
+	return b
+}
+
 // ModelAllInOneFile 配置是否将所有Model生成到一个文件
 // allInOne: 是否合并到一个文件
 // fileName: 文件名（当allInOne为true时有效）
@@ -136,6 +276,34 @@ func (b *ConfiggerBuilder) UseFramework(framework string) *ConfiggerBuilder {
 	return b
 }
 
+// TemplateDir 配置自定义模板目录，支持 ~ 符号表示用户目录
+// 目录中的 po.template、dto.template、vo.template、dao.template 和 tools/*.template 优先于内置模板，未提供的文件使用内置模板
+func (b *ConfiggerBuilder) TemplateDir(dir string) *ConfiggerBuilder {
+	b.config.GenerateOption.TemplateDir = tool.EscapeHomeDir(dir)
+	return b
+}
+
+// Artifacts 配置只生成指定的产物，不调用时生成模板集中的所有产物
+// 取值为产物类型（po、dto、vo、dao、tool 或模板集中的自定义类型），或 产物类型/模板名 选择单个工具模板
+// 例如 Artifacts("po", "dao", "tool/ptr") 只生成 PO、DAO 和 tool/ptr.go
+func (b *ConfiggerBuilder) Artifacts(artifacts ...string) *ConfiggerBuilder {
+	b.config.GenerateOption.Artifacts = artifacts
+	return b
+}
+
+// DryRun 配置只在内存中生成代码，向标准输出打印与 output_path 中已有文件的 diff，不写入任何文件
+func (b *ConfiggerBuilder) DryRun(dryRun bool) *ConfiggerBuilder {
+	b.config.GenerateOption.DryRun = dryRun
+	return b
+}
+
+// Check 配置检查 output_path 中的代码是否为最新，有文件需要新增或更新时生成返回错误，不写入任何文件
+// 适用于在 CI 中检查提交的代码是否与表结构一致
+func (b *ConfiggerBuilder) Check(check bool) *ConfiggerBuilder {
+	b.config.GenerateOption.Check = check
+	return b
+}
+
 // Packages 配置生成代码的包名
 // po: PO（持久化对象）包名
 // dto: DTO（数据传输对象）包名
@@ -206,24 +374,45 @@ func (b *ConfiggerBuilder) validate() error {
 	cfg := b.config
 
 	// 验证生成模式
-	if cfg.GenerateConfig.GenerateMode != "database" && cfg.GenerateConfig.GenerateMode != "statement" {
-		return fmt.Errorf("无效的生成模式: %s，必须是 'database' 或 'statement'", cfg.GenerateConfig.GenerateMode)
+	switch cfg.GenerateConfig.GenerateMode {
+	case "database", "statement", "sqlite":
+	default:
+		return fmt.Errorf("无效的生成模式: %s，必须是 'database'、'statement' 或 'sqlite'", cfg.GenerateConfig.GenerateMode)
 	}
 
 	// 验证数据库模式的必需参数
 	if cfg.GenerateConfig.GenerateMode == "database" {
-		if cfg.GenerateConfig.Host == "" {
-			return fmt.Errorf("数据库模式下必须指定 Host")
+		switch cfg.GenerateConfig.Dialect {
+		case "", "mysql", "postgres", "clickhouse":
+		default:
+			return fmt.Errorf("无效的数据库方言: %s，必须是 'mysql'、'postgres' 或 'clickhouse'", cfg.GenerateConfig.Dialect)
+		}
+		switch cfg.GenerateConfig.TLS.Mode {
+		case "", "disable", "require", "verify-ca", "verify-full":
+		default:
+			return fmt.Errorf("无效的 TLS 模式: %s，必须是 'disable'、'require'、'verify-ca' 或 'verify-full'", cfg.GenerateConfig.TLS.Mode)
 		}
-		if cfg.GenerateConfig.Port == 0 {
-			return fmt.Errorf("数据库模式下必须指定 Port")
+		if cfg.GenerateConfig.Socket != "" && cfg.GenerateConfig.Dialect == "clickhouse" {
+			return fmt.Errorf("clickhouse 不支持通过 unix socket 连接")
+		}
+		if err := cfg.GenerateConfig.validatePasswordSource(); err != nil {
+			return err
+		}
+		// 使用完整连接串时，主机、端口、用户名都由连接串提供
+		if cfg.GenerateConfig.DSN == "" {
+			if cfg.GenerateConfig.Host == "" && cfg.GenerateConfig.Socket == "" {
+				return fmt.Errorf("数据库模式下必须指定 Host 或 Socket")
+			}
+			if cfg.GenerateConfig.Port == 0 && cfg.GenerateConfig.Socket == "" {
+				return fmt.Errorf("数据库模式下必须指定 Port")
+			}
+			if cfg.GenerateConfig.Username == "" {
+				return fmt.Errorf("数据库模式下必须指定 Username")
+			}
 		}
 		if cfg.GenerateConfig.DatabaseName == "" {
 			return fmt.Errorf("数据库模式下必须指定 DatabaseName")
 		}
-		if cfg.GenerateConfig.Username == "" {
-			return fmt.Errorf("数据库模式下必须指定 Username")
-		}
 	}
 
 	// 验证SQL文件模式的必需参数
@@ -233,9 +422,19 @@ func (b *ConfiggerBuilder) validate() error {
 		}
 	}
 
+	// 验证SQLite模式的必需参数
+	if cfg.GenerateConfig.GenerateMode == "sqlite" {
+		if cfg.GenerateConfig.SqliteFilePath == "" {
+			return fmt.Errorf("SQLite模式下必须指定 SqliteFilePath")
+		}
+	}
+
 	// 验证表名配置
-	if !cfg.GenerateConfig.AllTables && len(cfg.GenerateConfig.TableNames) == 0 {
-		return fmt.Errorf("必须指定要生成的表名或使用 AllTables()")
+	if !cfg.GenerateConfig.AllTables && len(cfg.GenerateConfig.TableNames) == 0 && len(cfg.GenerateConfig.IncludeTables) == 0 {
+		return fmt.Errorf("必须指定要生成的表名、IncludeTables() 或使用 AllTables()")
+	}
+	if err := cfg.GenerateConfig.validateTablePatterns(); err != nil {
+		return err
 	}
 
 	// 验证输出路径
@@ -243,6 +442,28 @@ func (b *ConfiggerBuilder) validate() error {
 		return fmt.Errorf("必须指定输出路径")
 	}
 
+	for _, override := range cfg.GenerateOption.TypeOverrides {
+		if err := override.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := cfg.GenerateOption.TableNameTrim.validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.GenerateOption.validateNullableStyle(); err != nil {
+		return err
+	}
+
+	if err := cfg.GenerateOption.validateTemplateDir(); err != nil {
+		return err
+	}
+
+	if err := cfg.GenerateOption.validateArtifacts(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
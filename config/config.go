@@ -3,8 +3,13 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/LingoJack/model_infrax/tool"
+	"github.com/samber/lo"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,32 +20,220 @@ type Configger struct {
 }
 
 type GenerateConfig struct {
-	GenerateMode string `yaml:"generate_mode"` // 生成模式: database(从数据库解析) 或 statement(从SQL文件解析)
+	GenerateMode string `yaml:"generate_mode"` // 生成模式: database(从数据库解析)、statement(从SQL文件解析) 或 sqlite(从SQLite文件解析)
 
 	// database 模式配置
+	Dialect      string `yaml:"dialect"`       // 数据库方言: mysql(默认)、postgres 或 clickhouse
 	DatabaseName string `yaml:"database_name"` // 数据库名称
 	Host         string `yaml:"host"`          // 数据库主机地址
 	Port         int    `yaml:"port"`          // 数据库端口
-	URLTemplate  string `yaml:"url_template"`  // URL模板
+	URLTemplate  string `yaml:"url_template"`  // URL模板，按 用户名、密码、主机、端口、数据库名 的顺序填充 %s/%d 占位符
 	Username     string `yaml:"username"`      // 数据库用户名
 	Password     string `yaml:"password"`      // 数据库密码
+	SchemaName   string `yaml:"schema_name"`   // postgres 的 schema 名称，默认为 public
+
+	// database 模式连接配置
+	DSN            string        `yaml:"dsn"`             // 完整的连接串，配置后原样交给驱动，忽略 url_template 和其他连接配置
+	PasswordEnv    string        `yaml:"password_env"`    // 从该环境变量读取数据库密码
+	PasswordFile   string        `yaml:"password_file"`   // 从该文件读取数据库密码（去除首尾空白）
+	Socket         string        `yaml:"socket"`          // unix socket 路径（mysql 为 socket 文件，postgres 为 socket 所在目录），配置后忽略 host/port
+	ConnectTimeout time.Duration `yaml:"connect_timeout"` // 连接超时时间，如 5s，为 0 时使用驱动默认值
+	TLS            TLSConfig     `yaml:"tls"`             // TLS 配置
 
 	// statement 模式配置
-	SqlFilePath string `yaml:"sql_file_path"` // SQL文件路径
+	SqlFilePath string `yaml:"sql_file_path"` // SQL文件路径，也可以是迁移目录或 glob（如 ./migrations/*.up.sql）
+
+	// sqlite 模式配置
+	SqliteFilePath string `yaml:"sqlite_file_path"` // SQLite数据库文件路径
 
 	// 通用配置
-	AllTables  bool     `yaml:"all_tables"`  // 是否生成所有表
-	TableNames []string `yaml:"table_names"` // 表名列表
+	AllTables     bool     `yaml:"all_tables"`     // 是否生成所有表
+	TableNames    []string `yaml:"table_names"`    // 表名列表，精确匹配
+	IncludeTables []string `yaml:"include_tables"` // 需要生成的表名模式，支持 glob（如 t_order_*），以 re: 开头时为正则表达式
+	ExcludeTables []string `yaml:"exclude_tables"` // 需要跳过的表名模式，规则同 include_tables，优先级高于 all_tables、table_names、include_tables
+}
+
+// TableRegexpPrefix include_tables、exclude_tables 中正则表达式模式的前缀
+const TableRegexpPrefix = "re:"
+
+// validateTablePatterns 校验 include_tables、exclude_tables 中的 glob 和正则表达式是否合法
+func (c GenerateConfig) validateTablePatterns() error {
+	if err := validateTablePatterns("include_tables", c.IncludeTables); err != nil {
+		return err
+	}
+	return validateTablePatterns("exclude_tables", c.ExcludeTables)
+}
+
+func validateTablePatterns(key string, patterns []string) error {
+	for _, pattern := range patterns {
+		if expr, ok := strings.CutPrefix(pattern, TableRegexpPrefix); ok {
+			if _, err := regexp.Compile(expr); err != nil {
+				return fmt.Errorf("%s 中的正则表达式不合法 [%s]: %w", key, pattern, err)
+			}
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s 中的 glob 模式不合法 [%s]: %w", key, pattern, err)
+		}
+	}
+	return nil
+}
+
+// TLSConfig 数据库连接的 TLS 配置
+type TLSConfig struct {
+	Mode       string `yaml:"mode"`        // disable(默认)、require(加密但不校验证书)、verify-ca(校验证书链)、verify-full(校验证书链和主机名)
+	CAFile     string `yaml:"ca_file"`     // CA 证书文件路径
+	CertFile   string `yaml:"cert_file"`   // 客户端证书文件路径（双向认证时使用）
+	KeyFile    string `yaml:"key_file"`    // 客户端私钥文件路径（双向认证时使用）
+	ServerName string `yaml:"server_name"` // 校验证书时使用的主机名，默认为 host
+}
+
+// TLSEnabled 是否启用 TLS
+func (c TLSConfig) TLSEnabled() bool {
+	return c.Mode != "" && c.Mode != "disable"
+}
+
+// ResolvePassword 返回数据库密码
+// password、password_env、password_file 只能配置一个，避免密码以明文形式保存在配置文件中
+func (c GenerateConfig) ResolvePassword() (string, error) {
+	switch {
+	case c.PasswordEnv != "":
+		password, ok := os.LookupEnv(c.PasswordEnv)
+		if !ok {
+			return "", fmt.Errorf("环境变量 %s 未设置", c.PasswordEnv)
+		}
+		return password, nil
+	case c.PasswordFile != "":
+		byts, err := os.ReadFile(c.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("读取密码文件失败: %w", err)
+		}
+		return strings.TrimSpace(string(byts)), nil
+	default:
+		return c.Password, nil
+	}
+}
+
+// validatePasswordSource 校验密码来源只配置了一个
+func (c GenerateConfig) validatePasswordSource() error {
+	sources := lo.Filter([]string{c.Password, c.PasswordEnv, c.PasswordFile}, func(source string, _ int) bool {
+		return source != ""
+	})
+	if len(sources) > 1 {
+		return fmt.Errorf("password、password_env、password_file 只能配置其中一个")
+	}
+	return nil
 }
 
 type GenerateOption struct {
-	OutputPath            string        `yaml:"output_path"`              // 输出路径
-	IgnoreTableNamePrefix bool          `yaml:"ignore_table_name_prefix"` // 是否忽略表名前缀
-	CrudOnlyIdx           bool          `yaml:"crud_only_idx"`            // 是否仅CRUD索引
-	Package               PackageConfig `yaml:"package_name"`             // 包配置
-	ModelAllInOneFile     bool          `yaml:"all_model_in_one_file"`    // 是否将所有模型放在一个文件中
-	ModelAllInOneFileName string        `yaml:"all_model_in_one_file_name"`
-	UseFramework          string        `yaml:"use_framework"`
+	OutputPath            string         `yaml:"output_path"`              // 输出路径
+	ModulePath            string         `yaml:"module_path"`              // output_path 对应的 Go 导入路径，为空时从 output_path 所在的 go.mod 推导
+	IgnoreTableNamePrefix bool           `yaml:"ignore_table_name_prefix"` // 是否忽略表名前缀，开启后按 table_name_trim 去除表名的前缀、后缀
+	TableNameTrim         TableNameTrim  `yaml:"table_name_trim"`          // 表名前缀、后缀的去除规则
+	CrudOnlyIdx           bool           `yaml:"crud_only_idx"`            // 是否只为索引列生成查询条件（精确/IN/范围），并跳过模糊查询
+	TypeOverrides         []TypeOverride `yaml:"type_overrides"`           // 列的 Go 类型覆盖规则，优先于内置的类型映射
+	NullableStyle         string         `yaml:"nullable_style"`           // 可空列的 Go 类型风格: pointer（默认）、sql_null、generic
+	Package               PackageConfig  `yaml:"package_name"`             // 包配置
+	ModelAllInOneFile     bool           `yaml:"all_model_in_one_file"`    // 是否将所有模型放在一个文件中
+	ModelAllInOneFileName string         `yaml:"all_model_in_one_file_name"`
+	UseFramework          string         `yaml:"use_framework"`
+	TemplateDir           string         `yaml:"template_dir"` // 自定义模板目录，其中的 po/dto/vo/dao.template、tools/*.template 优先于内置模板
+	Artifacts             []string       `yaml:"artifacts"`    // 只生成指定的产物（如 po、dao、tool/ptr），为空时生成模板集中的所有产物
+	DryRun                bool           `yaml:"-"`            // 只打印生成的代码与已有文件的 diff，不写入文件，通过命令行 --dry-run 或 Builder 开启
+	Check                 bool           `yaml:"-"`            // 生成的代码与已有文件不一致时返回错误，不写入文件，通过命令行 --check 或 Builder 开启
+}
+
+// TableNameTrim 生成类型名、文件名时去除表名前缀、后缀的规则，生成的 TableName() 仍返回真实表名
+// 开启 ignore_table_name_prefix 且未配置任何规则时，默认去除 t_、tb_、tbl_ 前缀
+type TableNameTrim struct {
+	Prefixes []string `yaml:"prefixes"` // 前缀列表，按顺序匹配，只去除第一个匹配的前缀
+	Suffixes []string `yaml:"suffixes"` // 后缀列表，按顺序匹配，只去除第一个匹配的后缀
+	Regexps  []string `yaml:"regexps"`  // 正则表达式列表，去除前缀、后缀后依次将匹配的部分替换为空
+}
+
+// IsEmpty 是否未配置任何规则
+func (t TableNameTrim) IsEmpty() bool {
+	return len(t.Prefixes) == 0 && len(t.Suffixes) == 0 && len(t.Regexps) == 0
+}
+
+// validate 校验正则表达式是否合法
+func (t TableNameTrim) validate() error {
+	for _, pattern := range t.Regexps {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("table_name_trim.regexps 中的正则表达式不合法 [%s]: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// TypeOverride 将匹配的列映射为指定的 Go 类型
+// db_type 和 column 只能配置一个，按配置顺序匹配，column 规则优先于 db_type 规则
+type TypeOverride struct {
+	DbType         string `yaml:"db_type"`          // 按数据库类型匹配，支持 glob，忽略大小写，不带长度的模式同时匹配带长度的类型（decimal 匹配 decimal(10,2)）
+	Column         string `yaml:"column"`           // 按 表名.列名 匹配，支持 glob（如 t_order.extra、*.amount）
+	GoType         string `yaml:"go_type"`          // Go 类型，需要导入时写完整的导入路径（如 github.com/shopspring/decimal.Decimal）
+	NullableGoType string `yaml:"nullable_go_type"` // 列可空时的 Go 类型，为空时使用 go_type 的指针类型
+}
+
+// validate 校验类型覆盖规则是否完整、glob 模式是否合法
+func (o TypeOverride) validate() error {
+	if (o.DbType == "") == (o.Column == "") {
+		return fmt.Errorf("type_overrides 中的规则必须且只能配置 db_type、column 其中一个: %+v", o)
+	}
+	if o.GoType == "" {
+		return fmt.Errorf("type_overrides 中的规则缺少 go_type: %+v", o)
+	}
+	if o.Column != "" && !strings.Contains(o.Column, ".") {
+		return fmt.Errorf("type_overrides 中的 column 必须为 表名.列名 的形式: %s", o.Column)
+	}
+	if _, err := path.Match(strings.ToLower(o.DbType+o.Column), ""); err != nil {
+		return fmt.Errorf("type_overrides 中的 glob 模式不合法 [%s]: %w", o.DbType+o.Column, err)
+	}
+	return nil
+}
+
+// 可空列的 Go 类型风格
+const (
+	NullableStylePointer = "pointer"  // 指针（*string、*time.Time）
+	NullableStyleSqlNull = "sql_null" // database/sql 的 Null 类型（sql.NullString、sql.NullTime），没有对应类型的使用 sql.Null[T]
+	NullableStyleGeneric = "generic"  // 泛型 sql.Null[T]（Go 1.22+）
+)
+
+// validateNullableStyle 校验 nullable_style 是否为支持的取值，为空时使用 pointer
+func (o GenerateOption) validateNullableStyle() error {
+	switch o.NullableStyle {
+	case "", NullableStylePointer, NullableStyleSqlNull, NullableStyleGeneric:
+		return nil
+	default:
+		return fmt.Errorf("不支持的 nullable_style: %s，请使用 '%s'、'%s' 或 '%s'", o.NullableStyle, NullableStylePointer, NullableStyleSqlNull, NullableStyleGeneric)
+	}
+}
+
+// validateArtifacts 校验 artifacts 的格式: 产物类型（如 dao），或按模板目录生成的产物中的单个模板（如 tool/ptr）
+// 产物是否在模板集中声明由生成器检查
+func (o GenerateOption) validateArtifacts() error {
+	for _, artifact := range o.Artifacts {
+		kind, templateName, hasTemplate := strings.Cut(artifact, "/")
+		if kind == "" || (hasTemplate && (templateName == "" || strings.Contains(templateName, "/"))) {
+			return fmt.Errorf("artifacts 中的取值不合法: %q，请使用产物类型（如 dao）或 产物类型/模板名（如 tool/ptr）", artifact)
+		}
+	}
+	return nil
+}
+
+// validateTemplateDir 校验自定义模板目录是否存在，未配置时使用内置模板
+func (o GenerateOption) validateTemplateDir() error {
+	if o.TemplateDir == "" {
+		return nil
+	}
+	info, err := os.Stat(o.TemplateDir)
+	if err != nil {
+		return fmt.Errorf("读取自定义模板目录失败: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("template_dir 不是目录: %s", o.TemplateDir)
+	}
+	return nil
 }
 
 type PackageConfig struct {
@@ -67,10 +260,58 @@ func NewConfigger(configPath string) (*Configger, error) {
 	// 展开输出路径中的 ~ 符号
 	config.GenerateOption.OutputPath = tool.EscapeHomeDir(config.GenerateOption.OutputPath)
 
+	// 展开自定义模板目录中的 ~ 符号
+	if config.GenerateOption.TemplateDir != "" {
+		config.GenerateOption.TemplateDir = tool.EscapeHomeDir(config.GenerateOption.TemplateDir)
+	}
+
 	// 展开SQL文件路径中的 ~ 符号
 	if config.GenerateConfig.SqlFilePath != "" {
 		config.GenerateConfig.SqlFilePath = tool.EscapeHomeDir(config.GenerateConfig.SqlFilePath)
 	}
 
+	// 展开密码文件、socket 和证书路径中的 ~ 符号
+	generateConfig := &config.GenerateConfig
+	for _, path := range []*string{
+		&generateConfig.PasswordFile,
+		&generateConfig.Socket,
+		&generateConfig.TLS.CAFile,
+		&generateConfig.TLS.CertFile,
+		&generateConfig.TLS.KeyFile,
+	} {
+		if *path != "" {
+			*path = tool.EscapeHomeDir(*path)
+		}
+	}
+
+	if err = generateConfig.validatePasswordSource(); err != nil {
+		return nil, err
+	}
+	if err = config.GenerateOption.TableNameTrim.validate(); err != nil {
+		return nil, err
+	}
+	for _, override := range config.GenerateOption.TypeOverrides {
+		if err = override.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err = config.GenerateConfig.validateTablePatterns(); err != nil {
+		return nil, err
+	}
+	if err = config.GenerateOption.validateNullableStyle(); err != nil {
+		return nil, err
+	}
+	if err = config.GenerateOption.validateTemplateDir(); err != nil {
+		return nil, err
+	}
+	if err = config.GenerateOption.validateArtifacts(); err != nil {
+		return nil, err
+	}
+
+	// 展开SQLite文件路径中的 ~ 符号
+	if config.GenerateConfig.SqliteFilePath != "" {
+		config.GenerateConfig.SqliteFilePath = tool.EscapeHomeDir(config.GenerateConfig.SqliteFilePath)
+	}
+
 	return &config, nil
 }
@@ -0,0 +1,332 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrStaleFiles check 模式下生成的代码与 output_path 中的文件不一致
+var ErrStaleFiles = errors.New("生成的代码与 output_path 中的文件不一致")
+
+// diffContext 统一格式 diff 中每处修改前后保留的上下文行数，与 diff -u 相同
+const diffContext = 3
+
+// diffFiles 比较生成的代码与 output_path 中已有的文件，不写入任何文件
+// 生成代码所在的目录中不再生成的文件（如表被删除后遗留的旧文件）视为需要删除，见 removedFiles
+//   - dry_run: 向标准输出打印统一格式的 diff，新增、删除的文件与 /dev/null 比较，可以直接用 git apply 或 patch -p1 在 output_path 中应用
+//   - check: 有文件需要新增、更新或删除时返回 ErrStaleFiles，错误信息中列出这些文件
+func (g *Generator) diffFiles(files []generatedFile) error {
+	option := g.configger.GenerateOption
+	var stale []string
+	added := 0
+	for _, file := range files {
+		existing, err := os.ReadFile(file.path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("读取已有文件失败: %w", err)
+		}
+		if err == nil && bytes.Equal(existing, file.content) {
+			continue
+		}
+
+		relPath := filepath.ToSlash(g.relPath(file.path))
+		oldName := "a/" + relPath
+		if err != nil {
+			oldName = "/dev/null"
+			added++
+			stale = append(stale, fmt.Sprintf("  - %s（新增）", relPath))
+		} else {
+			stale = append(stale, fmt.Sprintf("  - %s（修改）", relPath))
+		}
+		if option.DryRun {
+			fmt.Fprint(os.Stdout, unifiedDiff(oldName, "b/"+relPath, existing, file.content))
+		}
+	}
+	modified := len(stale) - added
+
+	removed, err := g.removedFiles(files)
+	if err != nil {
+		return err
+	}
+	for _, filePath := range removed {
+		existing, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("读取已有文件失败: %w", err)
+		}
+		relPath := filepath.ToSlash(g.relPath(filePath))
+		stale = append(stale, fmt.Sprintf("  - %s（删除）", relPath))
+		if option.DryRun {
+			fmt.Fprint(os.Stdout, unifiedDiff("a/"+relPath, "/dev/null", existing, nil))
+		}
+	}
+	log.Printf("🔍 共 %d 个文件: 新增 %d 个，修改 %d 个，删除 %d 个，%d 个没有变化（未写入任何文件）", len(files), added, modified, len(removed), len(files)-added-modified)
+
+	if !option.Check || len(stale) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w，共 %d 个文件需要重新生成:\n%s", ErrStaleFiles, len(stale), strings.Join(stale, "\n"))
+}
+
+// removedFiles 返回生成代码所在的目录中已有、但本次不再生成的 .go 文件（如表被删除后遗留的旧文件），按路径排序
+// 这些目录视为由生成器管理，_test.go 文件除外；只选择了产物中的部分模板（如 tool/ptr）时该产物的目录不检查，
+// 与没有选中的产物共用的目录同样不检查，避免把其他产物的文件当作需要删除的文件
+func (g *Generator) removedFiles(files []generatedFile) ([]string, error) {
+	generated := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		generated[file.path] = true
+		if g.artifactFullySelected(file.kind) {
+			dirs[filepath.Dir(file.path)] = true
+		}
+	}
+	for _, artifact := range g.templateSet.Artifacts {
+		if !g.artifactFullySelected(artifact.Kind) {
+			delete(dirs, filepath.Join(g.configger.GenerateOption.OutputPath, g.packagePath(artifact.Kind)))
+		}
+	}
+
+	var removed []string
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取输出目录失败: %w", err)
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			if filePath := filepath.Join(dir, name); !generated[filePath] {
+				removed = append(removed, filePath)
+			}
+		}
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
+
+// relPath 返回生成的文件相对 output_path 的路径，无法计算时返回原路径
+func (g *Generator) relPath(filePath string) string {
+	relPath, err := filepath.Rel(g.configger.GenerateOption.OutputPath, filePath)
+	if err != nil {
+		return filePath
+	}
+	return relPath
+}
+
+// diffLine diff 中的一行，op 为 ' '（未修改）、'-'（删除）或 '+'（新增）
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// unifiedDiff 返回两个文件内容的统一格式 diff，内容相同时返回空字符串
+// 示例:
+//
+//	--- a/dao/t_user_dao.go
+//	+++ b/dao/t_user_dao.go
+//	@@ -10,7 +10,8 @@
+func unifiedDiff(oldName, newName string, oldContent, newContent []byte) string {
+	lines := diffLines(splitLines(oldContent), splitLines(newContent))
+
+	// oldCount[i]、newCount[i] 为 lines[:i] 中旧文件、新文件的行数，用于计算每段修改的起始行号
+	oldCount := make([]int, len(lines)+1)
+	newCount := make([]int, len(lines)+1)
+	for i, line := range lines {
+		oldCount[i+1], newCount[i+1] = oldCount[i], newCount[i]
+		if line.op != '+' {
+			oldCount[i+1]++
+		}
+		if line.op != '-' {
+			newCount[i+1]++
+		}
+	}
+
+	var buf strings.Builder
+	for start := 0; start < len(lines); {
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+
+		// 相邻两处修改之间的未修改行不超过两倍上下文时合并为一段
+		end := first
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			run := 0
+			for end+run < len(lines) && lines[end+run].op == ' ' {
+				run++
+			}
+			if end+run < len(lines) && run <= 2*diffContext {
+				end += run
+				continue
+			}
+			end += min(run, diffContext)
+			break
+		}
+		hunkStart := max(first-diffContext, start)
+
+		if buf.Len() == 0 {
+			fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n",
+			hunkRange(oldCount[hunkStart], oldCount[end]-oldCount[hunkStart]),
+			hunkRange(newCount[hunkStart], newCount[end]-newCount[hunkStart]))
+		for _, line := range lines[hunkStart:end] {
+			buf.WriteByte(line.op)
+			buf.WriteString(line.text)
+			buf.WriteByte('\n')
+		}
+		start = end
+	}
+	return buf.String()
+}
+
+// hunkRange 返回 diff 段头中的行号范围，没有行时起始行号为前一行（如新增文件为 0,0）
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// noNewlineMarker 文件不以换行符结尾时追加到最后一行，与以换行符结尾的同一行比较时视为不同，输出时成为 diff 中的标记行
+const noNewlineMarker = "\n\\ No newline at end of file"
+
+// splitLines 按行拆分文件内容，不包含换行符，文件不以换行符结尾时最后一行带有 noNewlineMarker
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	text, hasNewline := strings.CutSuffix(string(content), "\n")
+	lines := strings.Split(text, "\n")
+	if !hasNewline {
+		lines[len(lines)-1] += noNewlineMarker
+	}
+	return lines
+}
+
+// maxEditDistance diff 中最多计算的修改行数，超过时把中间修改的部分作为一整段替换输出，避免时间和内存随文件大小平方增长
+const maxEditDistance = 1000
+
+// diffLines 计算两组行之间的修改，先去掉相同的开头和结尾，只对中间修改的部分计算（见 myersDiff）
+func diffLines(oldLines, newLines []string) []diffLine {
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix && oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]diffLine, 0, len(oldLines)+len(newLines)-prefix-suffix)
+	for _, line := range oldLines[:prefix] {
+		lines = append(lines, diffLine{' ', line})
+	}
+	lines = append(lines, myersDiff(oldLines[prefix:len(oldLines)-suffix], newLines[prefix:len(newLines)-suffix])...)
+	for _, line := range oldLines[len(oldLines)-suffix:] {
+		lines = append(lines, diffLine{' ', line})
+	}
+	return lines
+}
+
+// myersDiff 使用 Myers 差分算法计算两组行之间修改最少的 diff，时间复杂度 O((N+M)D)，D 为修改的行数
+// D 超过 maxEditDistance 时不再计算，先删除全部旧行再新增全部新行
+func myersDiff(oldLines, newLines []string) []diffLine {
+	n, m := len(oldLines), len(newLines)
+	maxD := min(n+m, maxEditDistance)
+
+	// v[offset+k] 为对角线 k（x-y=k）上走得最远的 x，trace[d] 保存第 d 步结束后对角线 -d..d 上的 v，用于回溯
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && oldLines[x] == newLines[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackMyers(oldLines, newLines, trace)
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+
+	lines := make([]diffLine, 0, n+m)
+	for _, line := range oldLines {
+		lines = append(lines, diffLine{'-', line})
+	}
+	for _, line := range newLines {
+		lines = append(lines, diffLine{'+', line})
+	}
+	return lines
+}
+
+// backtrackMyers 从终点沿 trace 回溯，得到按顺序排列的 diff，trace 包含到达终点之前每一步的结果
+func backtrackMyers(oldLines, newLines []string, trace [][]int) []diffLine {
+	x, y := len(oldLines), len(newLines)
+	var reversed []diffLine
+	for d := len(trace); d > 0; d-- {
+		// previous[d-1+k] 为第 d-1 步结束后对角线 k 上的 x
+		previous := trace[d-1]
+		k := x - y
+		down := k == -d || (k != d && previous[d-1+k-1] < previous[d-1+k+1])
+		prevK := k - 1
+		if down {
+			prevK = k + 1
+		}
+		prevX := previous[d-1+prevK]
+		prevY := prevX - prevK
+
+		// 第 d 步从 (prevX, prevY) 新增一行（向下）或删除一行（向右），再沿对角线经过相同的行到达 (x, y)
+		midX := prevX + 1
+		if down {
+			midX = prevX
+		}
+		for x > midX {
+			x--
+			y--
+			reversed = append(reversed, diffLine{' ', oldLines[x]})
+		}
+		if down {
+			reversed = append(reversed, diffLine{'+', newLines[prevY]})
+		} else {
+			reversed = append(reversed, diffLine{'-', oldLines[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 {
+		x--
+		reversed = append(reversed, diffLine{' ', oldLines[x]})
+	}
+
+	lines := make([]diffLine, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
@@ -0,0 +1,192 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+)
+
+// TestDiffFiles dry-run 打印与已有文件的 diff，check 在代码过期时返回错误，两者都不写入文件
+func TestDiffFiles(t *testing.T) {
+	// 类型检查需要确定 output_path 的导入路径
+	outputPath := t.TempDir()
+	writeFiles := func(builder *config.ConfiggerBuilder) error {
+		g, schemas := newTestGenerator(t, `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_name varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);`, builder.OutputPath(outputPath).ModulePath("example.com/app/model"))
+		renderArtifacts(t, g, schemas)
+		return g.WriteFiles()
+	}
+	if err := writeFiles(config.NewBuilder()); err != nil {
+		t.Fatalf("生成代码失败: %v", err)
+	}
+	if err := writeFiles(config.NewBuilder().Check(true)); err != nil {
+		t.Errorf("代码为最新时 check 不应返回错误: %v", err)
+	}
+
+	// 修改一个已生成的文件、删除一个已生成的文件
+	poPath := filepath.Join(outputPath, "po/t_user.go")
+	content, _ := os.ReadFile(poPath)
+	edited := strings.Replace(string(content), "type TUser struct {", "type TUser struct { // edited", 1)
+	if err := os.WriteFile(poPath, []byte(edited), 0o644); err != nil {
+		t.Fatalf("修改生成的文件失败: %v", err)
+	}
+	if err := os.Remove(filepath.Join(outputPath, "tool/ptr.go")); err != nil {
+		t.Fatalf("删除生成的文件失败: %v", err)
+	}
+	// 表被删除后遗留的旧文件需要删除，手写的测试文件不受影响
+	for file, content := range map[string]string{
+		"po/t_old.go":       "package po\n\ntype TOld struct{}\n",
+		"po/t_user_test.go": "package po\n",
+	} {
+		if err := os.WriteFile(filepath.Join(outputPath, file), []byte(content), 0o644); err != nil {
+			t.Fatalf("写入已有文件失败: %v", err)
+		}
+	}
+
+	// dry-run 向标准输出打印 diff
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatalf("创建临时文件失败: %v", err)
+	}
+	originalStdout := os.Stdout
+	os.Stdout = stdout
+	err = writeFiles(config.NewBuilder().DryRun(true))
+	os.Stdout = originalStdout
+	if err != nil {
+		t.Fatalf("dry-run 失败: %v", err)
+	}
+	diff, _ := os.ReadFile(stdout.Name())
+	for _, expected := range []string{
+		"--- a/po/t_user.go\n+++ b/po/t_user.go\n@@ ",
+		"\n-type TUser struct { // edited\n+type TUser struct {\n",
+		"--- /dev/null\n+++ b/tool/ptr.go\n@@ -0,0 +1,",
+		"\n+package tool\n",
+		"--- a/po/t_old.go\n+++ /dev/null\n@@ -1,3 +0,0 @@\n-package po\n-\n-type TOld struct{}\n",
+	} {
+		if !strings.Contains(string(diff), expected) {
+			t.Errorf("dry-run 的 diff 缺少内容: %q\n%s", expected, diff)
+		}
+	}
+	for _, unexpected := range []string{"dao/t_user_dao.go", "t_user_test.go"} {
+		if strings.Contains(string(diff), unexpected) {
+			t.Errorf("没有变化的文件、测试文件不应出现在 diff 中: %s", unexpected)
+		}
+	}
+
+	err = writeFiles(config.NewBuilder().Check(true))
+	if !errors.Is(err, ErrStaleFiles) {
+		t.Fatalf("代码过期时 check 应返回 ErrStaleFiles: %v", err)
+	}
+	for _, expected := range []string{"po/t_user.go（修改）", "tool/ptr.go（新增）", "po/t_old.go（删除）"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("check 的错误信息缺少内容: %s\n%v", expected, err)
+		}
+	}
+
+	// 只选择了部分工具模板时，工具目录中其他模板生成的文件不是遗留的旧文件
+	err = writeFiles(config.NewBuilder().Check(true).Artifacts("po", "tool/ptr"))
+	if !errors.Is(err, ErrStaleFiles) || strings.Contains(err.Error(), "tool/str.go") || !strings.Contains(err.Error(), "po/t_old.go（删除）") {
+		t.Errorf("只选择部分工具模板时不应检查工具目录中的其他文件: %v", err)
+	}
+
+	// dry-run、check 都不写入文件
+	content, _ = os.ReadFile(poPath)
+	if string(content) != edited {
+		t.Errorf("dry-run、check 不应写入文件")
+	}
+	if _, err = os.Stat(filepath.Join(outputPath, "tool/ptr.go")); !os.IsNotExist(err) {
+		t.Errorf("dry-run、check 不应新增文件")
+	}
+	if _, err = os.Stat(filepath.Join(outputPath, "po/t_old.go")); err != nil {
+		t.Errorf("dry-run、check 不应删除文件: %v", err)
+	}
+}
+
+// TestDiffLines diff 由旧、新两组行组成，修改的行数最少；修改过多时整段替换
+func TestDiffLines(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, random.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(3)))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		oldLines, newLines := randomLines(), randomLines()
+		var gotOld, gotNew []string
+		edits := 0
+		for _, line := range diffLines(oldLines, newLines) {
+			if line.op != '+' {
+				gotOld = append(gotOld, line.text)
+			}
+			if line.op != '-' {
+				gotNew = append(gotNew, line.text)
+			}
+			if line.op != ' ' {
+				edits++
+			}
+		}
+		if strings.Join(gotOld, ",") != strings.Join(oldLines, ",") || strings.Join(gotNew, ",") != strings.Join(newLines, ",") {
+			t.Fatalf("diff 无法还原旧、新两组行: %v -> %v", oldLines, newLines)
+		}
+		if minEdits := len(oldLines) + len(newLines) - 2*lcsLength(oldLines, newLines); edits != minEdits {
+			t.Fatalf("diff 的修改行数不是最少的: %v -> %v, %d 行, 最少 %d 行", oldLines, newLines, edits, minEdits)
+		}
+	}
+
+	// 修改的行数超过 maxEditDistance 时整段替换
+	var oldContent, newContent strings.Builder
+	for i := 0; i < maxEditDistance; i++ {
+		fmt.Fprintf(&oldContent, "old %d\n", i)
+		fmt.Fprintf(&newContent, "new %d\n", i)
+	}
+	diff := unifiedDiff("a/po/t_user.go", "b/po/t_user.go", []byte(oldContent.String()), []byte(newContent.String()))
+	if !strings.Contains(diff, fmt.Sprintf("@@ -1,%d +1,%d @@\n-old 0\n", maxEditDistance, maxEditDistance)) {
+		t.Errorf("修改过多时应整段替换:\n%.200s", diff)
+	}
+}
+
+// lcsLength 返回两组行的最长公共子序列长度
+func lcsLength(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return lcs[0][0]
+}
+
+// TestUnifiedDiffNoNewline 文件不以换行符结尾时输出 \ No newline at end of file 标记
+func TestUnifiedDiffNoNewline(t *testing.T) {
+	for _, testCase := range []struct {
+		oldContent, newContent string
+		expected               string
+	}{
+		{"a\nb", "a\nb\n", "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n"},
+		{"a\nb\n", "a\nc", "@@ -1,2 +1,2 @@\n a\n-b\n+c\n\\ No newline at end of file\n"},
+		{"", "a", "@@ -0,0 +1,1 @@\n+a\n\\ No newline at end of file\n"},
+	} {
+		diff := unifiedDiff("a/x.go", "b/x.go", []byte(testCase.oldContent), []byte(testCase.newContent))
+		if !strings.HasSuffix(diff, testCase.expected) {
+			t.Errorf("%q -> %q 的 diff 不正确:\n%s\n期望:\n%s", testCase.oldContent, testCase.newContent, diff, testCase.expected)
+		}
+	}
+}
@@ -1,10 +1,89 @@
 package generator
 
-import "embed"
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
 
 //go:embed template/*.template
 //go:embed template/itea-go/*.template
+//go:embed template/clickhouse/*.template
 //go:embed template/tools/*.template
 var templateFS embed.FS
 
 const templatePathPrefix = "template/"
+
+// readTemplate 读取模板内容，自定义模板目录（template_dir）中存在对应的文件时优先使用，否则从模板集的文件系统中读取
+// 参数:
+//   - artifact: 模板所属的产物
+//   - templatePath: 模板在模板集中的路径（如 template/itea-go/po.template）
+//
+// 返回模板内容和实际读取的模板路径（自定义模板的文件路径或模板集中的路径），用于报告生成代码中的错误
+// 自定义模板目录中对应的文件见 templateOverridePath
+func (g *Generator) readTemplate(artifact Artifact, templatePath string) ([]byte, string, error) {
+	if templateDir := g.configger.GenerateOption.TemplateDir; templateDir != "" {
+		customPath := filepath.Join(templateDir, filepath.FromSlash(templateOverridePath(artifact, templatePath)))
+		content, err := os.ReadFile(customPath)
+		if err == nil {
+			log.Printf("📄 使用自定义模板: %s", customPath)
+			return content, customPath, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, "", fmt.Errorf("读取自定义模板文件失败: %w", err)
+		}
+	}
+
+	content, err := fs.ReadFile(g.templateSet.FS, templatePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("读取模板集 %s 中的模板文件失败: %w", g.templateSet.Name, err)
+	}
+	return content, templatePath, nil
+}
+
+// templateOverridePath 返回模板在自定义模板目录中对应的相对路径，模板根目录为产物模板路径的第一级目录（内置模板集为 template/）
+//   - 产物自身的模板按文件名覆盖，与框架无关: template/itea-go/po.template -> po.template
+//   - 方言模板和按模板目录生成的模板按相对模板根目录的路径覆盖，只有提供了该路径的文件时才覆盖:
+//     template/clickhouse/dao.template -> clickhouse/dao.template，template/tools/ptr.template -> tools/ptr.template
+func templateOverridePath(artifact Artifact, templatePath string) string {
+	if artifact.scope() != ScopeTemplates && templatePath == artifact.Template {
+		return path.Base(templatePath)
+	}
+	root, _, found := strings.Cut(artifact.Template, "/")
+	if !found {
+		return templatePath
+	}
+	return strings.TrimPrefix(templatePath, root+"/")
+}
+
+// templateNames 返回按模板目录生成的产物需要生成的模板文件名，包括模板集中的模板和自定义模板目录对应子目录中新增的模板，按文件名排序
+// 示例: 工具代码的模板目录为 template/tools，自定义模板目录中的 tools/*.template 会一起生成
+func (g *Generator) templateNames(artifact Artifact) ([]string, error) {
+	templateDir := g.artifactTemplate(artifact)
+	entries, err := fs.ReadDir(g.templateSet.FS, templateDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取模板集 %s 中的 %s 模板目录失败: %w", g.templateSet.Name, artifact.Kind, err)
+	}
+	if customDir := g.configger.GenerateOption.TemplateDir; customDir != "" {
+		customEntries, err := os.ReadDir(filepath.Join(customDir, filepath.FromSlash(templateOverridePath(artifact, templateDir))))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("读取自定义 %s 模板目录失败: %w", artifact.Kind, err)
+		}
+		entries = append(entries, customEntries...)
+	}
+
+	// 只处理 .template 文件，自定义模板目录中与模板集同名的文件只生成一次
+	names := lo.Uniq(lo.FilterMap(entries, func(entry fs.DirEntry, _ int) (string, bool) {
+		return entry.Name(), !entry.IsDir() && strings.HasSuffix(entry.Name(), ".template")
+	}))
+	sort.Strings(names)
+	return names, nil
+}
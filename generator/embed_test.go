@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+)
+
+// writeTemplates 把自定义模板写入模板目录（相对路径 -> 模板内容）
+func writeTemplates(t *testing.T, templateDir string, templates map[string]string) {
+	t.Helper()
+	for name, content := range templates {
+		path := filepath.Join(templateDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("创建模板目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("写入自定义模板失败: %v", err)
+		}
+	}
+}
+
+// TestReadTemplateFromTemplateDir 自定义模板目录中的模板覆盖同名的内置模板，未提供的模板使用内置模板
+func TestReadTemplateFromTemplateDir(t *testing.T) {
+	templateDir := t.TempDir()
+	writeTemplates(t, templateDir, map[string]string{
+		"vo.template": `package {{ .VoPackageName }}
+{{ range .Schemas }}
+// {{ .Name | EntityName }}Vo 自定义视图对象
+type {{ .Name | EntityName }}Vo struct {
+{{- range .Columns }}
+	{{ . | FieldName }} {{ . | GetGoType }} ` + "`json:\"{{ .ColumnName | ToCamelCase }}\"`" + `
+{{- end }}
+}
+{{ end }}`,
+		"tools/clock.template": "package tool\n\n// Clock 自定义工具\ntype Clock struct{}\n",
+	})
+	g, schemas := newTestGenerator(t, `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_name varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);`, config.NewBuilder().OutputPath(t.TempDir()).TemplateDir(templateDir))
+
+	assertContains(t, renderArtifacts(t, g, schemas), map[string][]string{
+		"vo/t_user_vo.go":   {"// TUserVo 自定义视图对象", `json:"userName"`},
+		"po/t_user.go":      {"type TUser struct"},
+		"tool/clock.go":     {"type Clock struct{}"},
+		"tool/ptr.go":       {"func StringPtr(v string) *string"},
+		"dao/t_user_dao.go": {"type TUserDao struct"},
+	})
+
+	if _, err := config.NewBuilder().StatementMode("schema.sql").AllTables().OutputPath(t.TempDir()).TemplateDir(filepath.Join(templateDir, "missing")).Build(); err == nil {
+		t.Errorf("自定义模板目录不存在时应返回错误")
+	}
+}
+
+// TestReadTemplateDialectOverride 方言模板只由自定义模板目录中相对模板根目录的同路径文件覆盖
+func TestReadTemplateDialectOverride(t *testing.T) {
+	templateDir := t.TempDir()
+	writeTemplates(t, templateDir, map[string]string{"dao.template": "custom dao"})
+	for _, framework := range []string{"", "itea-go"} {
+		g := NewGenerator(config.NewBuilder().StatementMode("schema.sql").AllTables().OutputPath(t.TempDir()).TemplateDir(templateDir).UseFramework(framework).MustBuild())
+		dao, _ := g.artifact(ArtifactDao)
+		clickhouseTemplate := dao.DialectTemplates["clickhouse"]
+
+		if _, source, err := g.readTemplate(dao, dao.Template); err != nil || source != filepath.Join(templateDir, "dao.template") {
+			t.Errorf("[%s] dao.template 应覆盖 DAO 模板: %s, %v", framework, source, err)
+		}
+		if _, source, err := g.readTemplate(dao, clickhouseTemplate); err != nil || source != clickhouseTemplate {
+			t.Errorf("[%s] 只提供 dao.template 时 ClickHouse 应使用内置模板: %s, %v", framework, source, err)
+		}
+
+		writeTemplates(t, templateDir, map[string]string{"clickhouse/dao.template": "custom clickhouse dao"})
+		if content, _, err := g.readTemplate(dao, clickhouseTemplate); err != nil || string(content) != "custom clickhouse dao" {
+			t.Errorf("[%s] clickhouse/dao.template 应覆盖 ClickHouse 的 DAO 模板: %s, %v", framework, content, err)
+		}
+		if err := os.RemoveAll(filepath.Join(templateDir, "clickhouse")); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
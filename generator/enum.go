@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
+)
+
+// enumIdentifierRegexp 匹配枚举值中不能出现在 Go 标识符中的字符
+var enumIdentifierRegexp = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// enumConstant 枚举类型的一个常量
+type enumConstant struct {
+	Name  string // 常量名（如 TOrderStatusDraft）
+	Value string // 枚举值（如 draft）
+}
+
+// ResolveEnumTypes 为 ENUM、SET 列确定生成的枚举类型名，写入 EnumTypeName
+// 类型名为 <实体名><字段名>（t_order.status -> TOrderStatus），与 PO、DTO、VO、DAO 中生成的类型重名时追加 Enum 后缀
+// 已被 type_overrides 覆盖类型的列、注释 @enum 写在非字符串列上时不生成枚举类型
+func (g *Generator) ResolveEnumTypes(schemas []model.Schema) []model.Schema {
+	usedNames := make(map[string]bool)
+	for _, schema := range schemas {
+		entityName := g.EntityName(schema.Name)
+		for _, suffix := range []string{"", "Builder", "Dto", "DtoBuilder", "Vo", "Dao"} {
+			usedNames[entityName+suffix] = true
+		}
+	}
+
+	for i := range schemas {
+		for j := range schemas[i].Columns {
+			column := &schemas[i].Columns[j]
+			column.EnumTypeName = ""
+			if len(column.EnumValues) == 0 || column.GoType != "" {
+				continue
+			}
+			// 注释 @enum 可以写在任意列上，枚举类型以字符串为底层类型，只支持映射为 string 的列
+			if goType := TrimPointer(dialectGoType(g.dialect(), *column)); goType != "string" {
+				log.Printf("⚠️ %s.%s 的 Go 类型为 %s，不支持生成枚举类型，已忽略 @enum", schemas[i].Name, column.ColumnName, goType)
+				continue
+			}
+			typeName := g.EntityName(schemas[i].Name) + FieldName(*column)
+			for usedNames[typeName] {
+				typeName += "Enum"
+			}
+			usedNames[typeName] = true
+			column.EnumTypeName = typeName
+		}
+		syncIndexColumns(&schemas[i])
+	}
+	return schemas
+}
+
+// EnumColumns 返回表中需要生成枚举类型的列
+func EnumColumns(schema model.Schema) []model.Column {
+	return lo.Filter(schema.Columns, func(column model.Column, _ int) bool {
+		return column.EnumTypeName != ""
+	})
+}
+
+// HasEnumColumns 判断是否有表需要生成枚举类型，用于决定 PO 文件的导入
+// setOnly 为 true 时只判断 SET 列
+func HasEnumColumns(schemas []model.Schema, setOnly bool) bool {
+	return lo.SomeBy(schemas, func(schema model.Schema) bool {
+		return lo.SomeBy(EnumColumns(schema), func(column model.Column) bool {
+			return !setOnly || IsSetType(column)
+		})
+	})
+}
+
+// IsSetType 判断列是否为 SET 类型，SET 的取值是逗号分隔的多个成员
+func IsSetType(column model.Column) bool {
+	return parseColumnType(column.Type).base == "set"
+}
+
+// EnumConstants 返回枚举类型的常量，常量名为 <类型名><枚举值>
+// 枚举值中不能用于标识符的字符会被去掉，空字符串使用 Empty，无法转换为标识符的使用 Value<序号>，去掉后重名时追加序号
+// 示例:
+//   - TOrderStatus + in-progress -> TOrderStatusInProgress
+//   - TOrderStatus + "" -> TOrderStatusEmpty
+//   - TOrderStatus + 已完成（第 5 个取值） -> TOrderStatusValue5
+func EnumConstants(column model.Column) []enumConstant {
+	usedNames := make(map[string]bool)
+	return lo.Map(column.EnumValues, func(value string, i int) enumConstant {
+		suffix := ToPascalCase(strings.Trim(enumIdentifierRegexp.ReplaceAllString(value, "_"), "_"))
+		if suffix == "" && value == "" {
+			suffix = "Empty"
+		} else if suffix == "" {
+			suffix = fmt.Sprintf("Value%d", i+1)
+		}
+		name := column.EnumTypeName + suffix
+		if usedNames[name] {
+			name = fmt.Sprintf("%s%d", name, i+1)
+		}
+		usedNames[name] = true
+		return enumConstant{Name: name, Value: value}
+	})
+}
+
+// goType 返回列在指定包中使用的 Go 类型
+//   - 可空列按 nullable_style 使用指针或 sql.Null* 类型；VO 用于接口返回，sql.Null* 没有实现 JSON 序列化，始终使用指针
+//   - 枚举类型定义在 PO 包中，DTO、VO、DAO 等其他包引用时需要加上 PO 包名（如 *po.TOrderStatus）
+func (g *Generator) goType(column model.Column, packagePath string) string {
+	goType := dialectGoType(g.dialect(), column)
+	option := g.configger.GenerateOption
+	if column.IsNullable && column.GoType == "" && packagePath != g.packagePath(ArtifactVo) {
+		goType = nullableStyleGoType(option.NullableStyle, goType)
+	}
+	poPackage := g.packagePath(ArtifactPo)
+	if column.EnumTypeName == "" || column.GoType != "" || packagePath == poPackage {
+		return goType
+	}
+	return strings.Replace(goType, column.EnumTypeName, getPackageName(poPackage)+"."+column.EnumTypeName, 1)
+}
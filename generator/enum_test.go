@@ -0,0 +1,24 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+)
+
+// TestResolveEnumTypes 为 ENUM、SET 列生成枚举类型和常量，其他包通过 PO 包名引用
+func TestResolveEnumTypes(t *testing.T) {
+	g, schemas := newTestGenerator(t, `CREATE TABLE t_order (
+  id bigint NOT NULL AUTO_INCREMENT,
+  status enum('draft','in-progress','done') NOT NULL DEFAULT 'draft',
+  tags set('hot','new') DEFAULT NULL,
+  PRIMARY KEY (id),
+  KEY idx_status (status)
+);`, config.NewBuilder().OutputPath(t.TempDir()))
+
+	assertContains(t, renderArtifacts(t, g, schemas), map[string][]string{
+		"po/t_order.go":      {"type TOrderStatus string", `TOrderStatusInProgress TOrderStatus = "in-progress"`, " TOrderStatus ", " *TOrderTags ", "func (e TOrderTags) IsValid() bool", "func (e *TOrderStatus) Scan(value any) error"},
+		"dto/t_order_dto.go": {" po.TOrderStatus ", " *po.TOrderTags "},
+		"dao/t_order_dao.go": {`queryDto.Status != ""`},
+	})
+}
@@ -3,34 +3,43 @@ package generator
 import (
 	"bytes"
 	"fmt"
-	"go/format"
 	"log"
-
-	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"text/template"
 
 	"github.com/LingoJack/model_infrax/config"
 	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
 )
 
 // Generator 代码生成器
 type Generator struct {
-	modelTemplatePath string            // 模板文件路径（嵌入式路径）
-	daoTemplatePath   string            // dao文件路径（嵌入式路径）
-	dtoTemplatePath   string            // dto文件路径（嵌入式路径）
-	voTemplatePath    string            // vo文件路径（嵌入式路径）
-	toolTemplateDir   string            // tool文件路径（嵌入式路径）
-	configger         *config.Configger // 配置对象
+	templateSet      TemplateSet       // 使用的模板集，由 use_framework 指定
+	configger        *config.Configger // 配置对象
+	tableNameRegexps []*regexp.Regexp  // 生成结构体名时从表名中去除的正则表达式
+	entityNames      map[string]string // 表注释 @name 指定的结构体名（表名 -> 结构体名），由 CheckEntityNames 记录
+	outputImportPath string            // output_path 对应的 Go 导入路径，无法确定时为空
+	files            []generatedFile   // 已生成、等待 WriteFiles 检查并写入的文件
 }
 
 // TemplateData 传递给模板的数据结构
 type TemplateData struct {
+	PackageName    string         // 生成的文件所在的包名，自定义产物通过它声明 package
 	PoPackageName  string         // po 包名（从路径最后一段提取）
 	DtoPackageName string         // dto 包名（从路径最后一段提取）
 	VoPackageName  string         // vo 包名（从路径最后一段提取）
 	DaoPackageName string         // dao 包名（从路径最后一段提取）
+	Dialect        string         // 数据库方言（mysql/postgres/clickhouse/sqlite），statement 模式下为 mysql
+	CrudOnlyIdx    bool           // 是否只为索引列生成查询条件，开启后不生成无法使用索引的模糊查询
+	PoImportPath   string         // po 包的完整导入路径，无法确定 output_path 的导入路径时为空
+	DtoImportPath  string         // dto 包的完整导入路径
+	VoImportPath   string         // vo 包的完整导入路径
+	DaoImportPath  string         // dao 包的完整导入路径
+	ToolImportPath string         // tool 包的完整导入路径
+	Imports        []string       // 类型覆盖、可空类型需要的额外导入路径
 	Schemas        []model.Schema // 表结构列表
 }
 
@@ -41,392 +50,256 @@ type TemplateData struct {
 // 返回:
 //   - *Generator: 生成器实例
 func NewGenerator(cfg *config.Configger) *Generator {
-	// 使用嵌入式模板路径（相对于 embed.FS 的根路径）
-	// 由于 embed.go 在 generator 目录下，需要使用 ../assert/template/ 前缀
-	generator := Generator{
-		modelTemplatePath: templatePathPrefix + "po.template",
-		daoTemplatePath:   templatePathPrefix + "dao.template",
-		dtoTemplatePath:   templatePathPrefix + "dto.template",
-		voTemplatePath:    templatePathPrefix + "vo.template",
-		toolTemplateDir:   templatePathPrefix + "tools",
-		configger:         cfg,
-	}
-
-	// 如果使用 itea-go 框架，切换到对应的模板路径
-	if cfg.GenerateOption.UseFramework == "itea-go" {
-		generator = Generator{
-			modelTemplatePath: templatePathPrefix + "itea-go/po.template",
-			daoTemplatePath:   templatePathPrefix + "itea-go/dao.template",
-			dtoTemplatePath:   templatePathPrefix + "itea-go/dto.template",
-			voTemplatePath:    templatePathPrefix + "itea-go/vo.template",
-			toolTemplateDir:   templatePathPrefix + "tools",
-			configger:         cfg,
-		}
+	// 按 use_framework 选择已注册的模板集，未注册时使用默认的 gorm 模板集（由 CheckFramework 报告）
+	// 配置了 template_dir 时，读取模板时优先使用其中的同名模板（见 readTemplate）
+	templateSet, ok := LookupTemplateSet(cfg.GenerateOption.UseFramework)
+	if !ok {
+		log.Printf("⚠️ 未注册的框架: %s，使用 %s 模板", cfg.GenerateOption.UseFramework, DefaultFramework)
+		templateSet, _ = LookupTemplateSet(DefaultFramework)
+	}
+
+	// 生成的 DTO、VO、DAO 需要通过完整的导入路径引用 PO、DTO 包
+	outputImportPath, err := resolveOutputImportPath(cfg.GenerateOption.ModulePath, cfg.GenerateOption.OutputPath)
+	switch {
+	case err != nil:
+		log.Printf("⚠️ 解析 output_path 的导入路径失败: %v，生成的代码无法通过类型检查", err)
+	case outputImportPath == "":
+		log.Println("⚠️ 未配置 module_path，output_path 及其上级目录中也没有 go.mod，生成的代码无法通过类型检查")
+	default:
+		log.Printf("📦 生成代码的导入路径: %s", outputImportPath)
+	}
+
+	return &Generator{
+		templateSet:      templateSet,
+		configger:        cfg,
+		tableNameRegexps: compileTableNameRegexps(cfg.GenerateOption.TableNameTrim.Regexps),
+		outputImportPath: outputImportPath,
 	}
-
-	return &generator
 }
 
-// GenerateModelOneByOne 根据模板生成代码，每个表生成一个文件
-// 参数:
-//   - schemas: 表结构列表
-//
-// 返回:
-//   - error: 生成过程中的错误
-func (g *Generator) GenerateModelOneByOne(schemas []model.Schema) (err error) {
-	for _, schema := range schemas {
-		fileName := fmt.Sprintf("%s.go", schema.Name)
-		err = g.GenerateModel([]model.Schema{schema}, fileName)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+// templateFuncs 返回注册到所有模板中的函数
+// QuoteColumn 依赖当前的数据库方言，因此每个生成器实例单独构建
+// GetGoType 依赖生成文件所在的包（packagePath），其他包引用 PO 包中的枚举类型时需要加上包名
+// 模板集声明的额外函数与内置函数同名时覆盖内置函数
+func (g *Generator) templateFuncs(packagePath string) template.FuncMap {
+	dialect := g.dialect()
+	return lo.Assign(template.FuncMap{
+		"ToPascalCase":      ToPascalCase,
+		"EntityName":        g.EntityName,
+		"FieldName":         FieldName,
+		"ToCamelCase":       ToCamelCase,
+		"ToSafeParamName":   ToSafeParamName,
+		"TrimPointer":       TrimPointer,
+		"IsPointer":         IsPointer,
+		"IsNillable":        IsNillable,
+		"IsNullWrapper":     IsNullWrapper,
+		"NullBaseType":      NullBaseType,
+		"NullValue":         NullValue,
+		"StdImports":        StdImports,
+		"ThirdPartyImports": ThirdPartyImports,
+		"GetGoType": func(column model.Column) string {
+			return g.goType(column, packagePath)
+		},
+		"QuoteColumn": func(columnName string) string {
+			return QuoteColumn(dialect, columnName)
+		},
+		"TimeRangeColumn": TimeRangeColumn,
+		"QueryColumns":    g.queryColumns,
+		"UniqueIndexes":   UniqueIndexes,
+		"NormalIndexes":   NormalIndexes,
+		"NeedsReflect":    g.needsReflect,
+		"EnumColumns":     EnumColumns,
+		"EnumConstants":   EnumConstants,
+		"HasEnumColumns":  HasEnumColumns,
+		"IsSetType":       IsSetType,
+	}, g.templateSet.Funcs)
 }
 
-// GenerateModel 生成所有表到一个文件
-// 参数:
-//   - schemas: 表结构列表
-//   - outputFileName: 输出文件名
-//
-// 返回:
-//   - error: 生成过程中的错误
-func (g *Generator) GenerateModel(schemas []model.Schema, outputFileName string) (err error) {
-
-	// 从嵌入的文件系统中读取模板文件
-	tmplContent, err := templateFS.ReadFile(g.modelTemplatePath)
-	if err != nil {
-		return fmt.Errorf("读取嵌入式模板文件失败: %w", err)
+// dialect 返回生成代码所面向的数据库方言
+// sqlite 模式固定为 sqlite，database 模式读取 dialect 配置，其余情况按 MySQL 处理
+func (g *Generator) dialect() string {
+	if g.configger.GenerateConfig.GenerateMode == "sqlite" {
+		return "sqlite"
 	}
-
-	// 创建模板并注册函数
-	tmpl, err := template.New("model").Funcs(template.FuncMap{
-		"ToPascalCase":    ToPascalCase,
-		"ToCamelCase":     ToCamelCase,
-		"ToSafeParamName": ToSafeParamName,
-		"TrimPointer":     TrimPointer,
-		"GetGoType":       GetGoType,
-	}).Parse(string(tmplContent))
-	if err != nil {
-		return fmt.Errorf("解析模板失败: %w", err)
-	}
-
-	// 从配置中获取输出路径（已在配置解析时展开 ~ 符号）
-	outputPath := filepath.Join(g.configger.GenerateOption.OutputPath, g.configger.GenerateOption.Package.PoPackage)
-
-	// 确保输出目录存在
-	if err = os.MkdirAll(outputPath, 0755); err != nil {
-		return fmt.Errorf("创建输出目录失败: %w", err)
-	}
-
-	// 生成文件路径
-	filePath := filepath.Join(outputPath, outputFileName)
-
-	// 准备模板数据，包含包名和表结构
-	templateData := TemplateData{
-		DaoPackageName: getPackageName(g.configger.GenerateOption.Package.DaoPackage),
-		PoPackageName:  getPackageName(g.configger.GenerateOption.Package.PoPackage),
-		DtoPackageName: getPackageName(g.configger.GenerateOption.Package.DtoPackage),
-		Schemas:        schemas,
-	}
-
-	// 先将模板执行结果写入缓冲区
-	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, templateData)
-	if err != nil {
-		return fmt.Errorf("执行模板失败: %w", err)
-	}
-
-	// 使用 go/format 格式化代码
-	formattedCode, err := format.Source(buf.Bytes())
-	if err != nil {
-		// 如果格式化失败，记录警告但仍然写入未格式化的代码
-		log.Printf("警告: 格式化代码失败: %v，将写入未格式化的代码\n", err)
-		formattedCode = buf.Bytes()
-	}
-
-	// 创建输出文件并写入格式化后的代码
-	err = os.WriteFile(filePath, formattedCode, 0644)
-	if err != nil {
-		return fmt.Errorf("写入输出文件失败: %w", err)
+	if g.configger.GenerateConfig.GenerateMode == "database" && g.configger.GenerateConfig.Dialect != "" {
+		return g.configger.GenerateConfig.Dialect
 	}
-
-	log.Printf("成功生成文件: %s\n", filePath)
-	return nil
+	return "mysql"
 }
 
-// GenerateDTOOneByOne 根据模板生成 DTO 代码，每个表生成一个文件
-// 参数:
-//   - schemas: 表结构列表
-//
-// 返回:
-//   - error: 生成过程中的错误
-func (g *Generator) GenerateDTOOneByOne(schemas []model.Schema) (err error) {
-	for _, schema := range schemas {
-		fileName := fmt.Sprintf("%s_dto.go", schema.Name)
-		err = g.GenerateDTO([]model.Schema{schema}, fileName)
-		if err != nil {
-			return err
+// queryColumns 返回可以作为 DTO 查询条件的列，列注释 @json 指定的 JSON 字段不能按值比较，不作为查询条件
+// 开启 crud_only_idx 时只返回索引列（含列定义中直接声明的主键、唯一键），避免通过生成的 DAO 写出全表扫描的查询
+func (g *Generator) queryColumns(schema model.Schema) []model.Column {
+	return lo.Filter(schema.Columns, func(column model.Column, _ int) bool {
+		if column.JsonType != "" {
+			return false
 		}
-	}
-	return nil
+		return !g.configger.GenerateOption.CrudOnlyIdx || column.IsIndexed || column.IsPrimaryKey || column.IsUnique
+	})
 }
 
-// GenerateDTO 生成 DTO 文件
-// 参数:
-//   - schemas: 表结构列表
-//   - outputFileName: 输出文件名
-//
-// 返回:
-//   - error: 生成过程中的错误
-func (g *Generator) GenerateDTO(schemas []model.Schema, outputFileName string) (err error) {
-	// 从嵌入的文件系统中读取 DTO 模板文件
-	tmplContent, err := templateFS.ReadFile(g.dtoTemplatePath)
-	if err != nil {
-		return fmt.Errorf("读取嵌入式 DTO 模板文件失败: %w", err)
-	}
-
-	// 创建模板并注册函数
-	tmpl, err := template.New("dto").Funcs(template.FuncMap{
-		"ToPascalCase":    ToPascalCase,
-		"ToCamelCase":     ToCamelCase,
-		"ToSafeParamName": ToSafeParamName,
-		"TrimPointer":     TrimPointer,
-		"GetGoType":       GetGoType,
-	}).Parse(string(tmplContent))
-	if err != nil {
-		return fmt.Errorf("解析 DTO 模板失败: %w", err)
-	}
-
-	// 从配置中获取输出路径（已在配置解析时展开 ~ 符号）
-	outputPath := filepath.Join(g.configger.GenerateOption.OutputPath, g.configger.GenerateOption.Package.DtoPackage)
-
-	// 确保输出目录存在
-	if err = os.MkdirAll(outputPath, 0755); err != nil {
-		return fmt.Errorf("创建 DTO 输出目录失败: %w", err)
-	}
-
-	// 生成文件路径
-	filePath := filepath.Join(outputPath, outputFileName)
-
-	// 准备模板数据，包含包名和表结构
-	templateData := TemplateData{
-		DaoPackageName: getPackageName(g.configger.GenerateOption.Package.DaoPackage),
-		PoPackageName:  getPackageName(g.configger.GenerateOption.Package.PoPackage),
-		DtoPackageName: getPackageName(g.configger.GenerateOption.Package.DtoPackage),
-		Schemas:        schemas,
-	}
-
-	// 先将模板执行结果写入缓冲区
-	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, templateData)
-	if err != nil {
-		return fmt.Errorf("执行 DTO 模板失败: %w", err)
-	}
-
-	// 使用 go/format 格式化代码
-	formattedCode, err := format.Source(buf.Bytes())
-	if err != nil {
-		// 如果格式化失败，记录警告但仍然写入未格式化的代码
-		log.Printf("警告: 格式化 DTO 代码失败: %v，将写入未格式化的代码\n", err)
-		formattedCode = buf.Bytes()
-	}
-
-	// 创建输出文件并写入格式化后的代码
-	err = os.WriteFile(filePath, formattedCode, 0644)
-	if err != nil {
-		return fmt.Errorf("写入 DTO 输出文件失败: %w", err)
-	}
-
-	log.Printf("成功生成 DTO 文件: %s\n", filePath)
-	return nil
+// indexMethodSuffix 返回按索引生成的方法名后缀（如 TenantIdAndName），列相同的索引生成的方法同名
+func indexMethodSuffix(index model.Index) string {
+	return strings.Join(lo.Map(index.Columns, func(column model.Column, _ int) string {
+		return FieldName(column)
+	}), "And")
 }
 
-// GenerateTool 生成工具文件
-// 参数:
-//   - templateFileName: 模板文件名（如 "ptr.template"）
-//   - outputFileName: 输出文件名（如 "ptr.go"）
-//
-// 返回:
-//   - error: 生成过程中的错误
-func (g *Generator) GenerateTool(templateFileName, outputFileName string) (err error) {
-	// 构建嵌入式模板文件路径
-	templatePath := filepath.Join(g.toolTemplateDir, templateFileName)
-
-	// 从嵌入的文件系统中读取模板文件
-	tmplContent, err := templateFS.ReadFile(templatePath)
-	if err != nil {
-		return fmt.Errorf("读取嵌入式工具模板文件失败: %w", err)
-	}
-
-	// 创建模板并注册函数
-	tmpl, err := template.New("tool").Funcs(template.FuncMap{
-		"ToPascalCase":    ToPascalCase,
-		"ToCamelCase":     ToCamelCase,
-		"ToSafeParamName": ToSafeParamName,
-		"TrimPointer":     TrimPointer,
-		"GetGoType":       GetGoType,
-	}).Parse(string(tmplContent))
-	if err != nil {
-		return fmt.Errorf("解析工具模板失败: %w", err)
-	}
-
-	// 从配置中获取输出路径（已在配置解析时展开 ~ 符号）
-	outputPath := filepath.Join(g.configger.GenerateOption.OutputPath, g.configger.GenerateOption.Package.ToolPackage)
-
-	// 确保输出目录存在
-	if err = os.MkdirAll(outputPath, 0755); err != nil {
-		return fmt.Errorf("创建工具输出目录失败: %w", err)
-	}
-
-	// 生成文件路径
-	filePath := filepath.Join(outputPath, outputFileName)
-
-	// 先将模板执行结果写入缓冲区
-	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, nil)
-	if err != nil {
-		return fmt.Errorf("执行工具模板失败: %w", err)
-	}
+// methodIndexes 按 单列主键、唯一索引、普通索引 的顺序去掉会生成同名方法的索引
+// 只有单列主键会生成 SelectBy 等方法，联合主键不占用方法名
+func methodIndexes(schema model.Schema) (uniqueIndexes, normalIndexes []model.Index) {
+	generated := make(map[string]bool)
+	if len(schema.PrimaryKey.Columns) == 1 {
+		generated[indexMethodSuffix(schema.PrimaryKey)] = true
+	}
+	dedup := func(indexes []model.Index) []model.Index {
+		return lo.Filter(indexes, func(index model.Index, _ int) bool {
+			suffix := indexMethodSuffix(index)
+			if len(index.Columns) == 0 || index.IndexName == "PRIMARY" || generated[suffix] {
+				return false
+			}
+			generated[suffix] = true
+			return true
+		})
+	}
+	uniqueIndexes = dedup(schema.UniqueIndex)
+	normalIndexes = dedup(schema.Indexes)
+	return uniqueIndexes, normalIndexes
+}
 
-	// 使用 go/format 格式化代码
-	formattedCode, err := format.Source(buf.Bytes())
-	if err != nil {
-		// 如果格式化失败，记录警告但仍然写入未格式化的代码
-		log.Printf("警告: 格式化工具代码失败: %v，将写入未格式化的代码\n", err)
-		formattedCode = buf.Bytes()
-	}
+// UniqueIndexes 返回需要生成方法的唯一索引，与单列主键或前面的唯一索引列相同的跳过，避免重复声明方法
+func UniqueIndexes(schema model.Schema) []model.Index {
+	uniqueIndexes, _ := methodIndexes(schema)
+	return uniqueIndexes
+}
 
-	// 创建输出文件并写入格式化后的代码
-	err = os.WriteFile(filePath, formattedCode, 0644)
-	if err != nil {
-		return fmt.Errorf("写入工具输出文件失败: %w", err)
-	}
+// NormalIndexes 返回需要生成方法的普通索引，与单列主键、唯一索引或前面的普通索引列相同的跳过，避免重复声明方法
+func NormalIndexes(schema model.Schema) []model.Index {
+	_, normalIndexes := methodIndexes(schema)
+	return normalIndexes
+}
 
-	log.Printf("成功生成工具文件: %s\n", filePath)
-	return nil
+// needsReflect 判断生成的查询条件是否需要 reflect 判断零值
+// 只有 type_overrides 指定的、不能与 nil 比较的类型（如 decimal.Decimal）需要
+func (g *Generator) needsReflect(schemas []model.Schema) bool {
+	return lo.SomeBy(schemas, func(schema model.Schema) bool {
+		return lo.SomeBy(g.queryColumns(schema), func(column model.Column) bool {
+			return column.GoType != "" && !IsNillable(column.GoType)
+		})
+	})
 }
 
-// GenerateDAOOneByOne 根据模板生成 DAO 代码，每个表生成一个文件
+// GenerateArtifact 按产物的生成方式执行模板，生成的代码暂存在内存中，由 WriteFiles 检查后统一写入
+//   - table: 每张表生成一个文件
+//   - all: 所有表生成到一个文件
+//   - templates: 模板目录中的每个 .template 生成一个同名的 .go 文件
+//
 // 参数:
+//   - artifact: 需要生成的产物，来自 Artifacts
 //   - schemas: 表结构列表
 //
 // 返回:
 //   - error: 生成过程中的错误
-func (g *Generator) GenerateDAOOneByOne(schemas []model.Schema) (err error) {
-	for _, schema := range schemas {
-		fileName := fmt.Sprintf("%s_dao.go", schema.Name)
-		err = g.GenerateDAO([]model.Schema{schema}, fileName)
+func (g *Generator) GenerateArtifact(artifact Artifact, schemas []model.Schema) (err error) {
+	templatePath := g.artifactTemplate(artifact)
+	switch artifact.scope() {
+	case ScopeTable:
+		for _, schema := range schemas {
+			outputFileName := fmt.Sprintf(artifact.FileName, g.entityBaseName(schema.Name))
+			err = g.render(artifact, templatePath, []model.Schema{schema}, outputFileName)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case ScopeAll:
+		return g.render(artifact, templatePath, schemas, artifact.FileName)
+	case ScopeTemplates:
+		// 读取模板列表，包括自定义模板目录中新增的模板
+		templateFileNames, err := g.templateNames(artifact)
 		if err != nil {
 			return err
 		}
+		for _, templateFileName := range templateFileNames {
+			// artifacts 中只选择了部分模板（如 tool/ptr）时跳过其他模板
+			if !g.templateSelected(artifact.Kind, templateFileName) {
+				continue
+			}
+			// 生成输出文件名（将 .template 替换为 .go）
+			outputFileName := strings.TrimSuffix(templateFileName, ".template") + ".go"
+			err = g.render(artifact, path.Join(templatePath, templateFileName), schemas, outputFileName)
+			if err != nil {
+				return fmt.Errorf("生成 %s 文件 %s 失败: %w", artifact.Kind, outputFileName, err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("产物 %s 的生成方式不支持: %s", artifact.Kind, artifact.Scope)
 	}
-	return nil
 }
 
-// GenerateDAO 生成 DAO 文件
+// render 执行产物的一个模板，把生成的代码暂存到待写入的文件列表中
 // 参数:
-//   - schemas: 表结构列表
+//   - artifact: 模板所属的产物
+//   - templatePath: 模板在模板集中的路径，自定义模板目录中的同名模板优先
+//   - schemas: 传递给模板的表结构列表
 //   - outputFileName: 输出文件名
 //
 // 返回:
-//   - error: 生成过程中的错误
-func (g *Generator) GenerateDAO(schemas []model.Schema, outputFileName string) (err error) {
-	// 从嵌入的文件系统中读取 DAO 模板文件
-	tmplContent, err := templateFS.ReadFile(g.daoTemplatePath)
+//   - error: 读取、解析或执行模板的错误
+func (g *Generator) render(artifact Artifact, templatePath string, schemas []model.Schema, outputFileName string) error {
+	// 读取模板文件，自定义模板目录中的同名模板优先
+	tmplContent, templateSource, err := g.readTemplate(artifact, templatePath)
 	if err != nil {
-		return fmt.Errorf("读取嵌入式 DAO 模板文件失败: %w", err)
+		return fmt.Errorf("读取 %s 模板失败: %w", artifact.Kind, err)
 	}
 
 	// 创建模板并注册函数
-	tmpl, err := template.New("dao").Funcs(template.FuncMap{
-		"ToPascalCase":    ToPascalCase,
-		"ToCamelCase":     ToCamelCase,
-		"ToSafeParamName": ToSafeParamName,
-		"TrimPointer":     TrimPointer,
-		"GetGoType":       GetGoType,
-	}).Parse(string(tmplContent))
+	packagePath := g.packagePath(artifact.Kind)
+	tmpl, err := template.New(artifact.Kind).Funcs(g.templateFuncs(packagePath)).Parse(string(tmplContent))
 	if err != nil {
-		return fmt.Errorf("解析 DAO 模板失败: %w", err)
+		return fmt.Errorf("解析 %s 模板失败: %w", artifact.Kind, err)
 	}
 
-	// 从配置中获取输出路径（已在配置解析时展开 ~ 符号）
-	outputPath := filepath.Join(g.configger.GenerateOption.OutputPath, g.configger.GenerateOption.Package.DaoPackage)
-
-	// 确保输出目录存在
-	if err = os.MkdirAll(outputPath, 0755); err != nil {
-		return fmt.Errorf("创建 DAO 输出目录失败: %w", err)
-	}
-
-	// 生成文件路径
-	filePath := filepath.Join(outputPath, outputFileName)
-
-	// 准备模板数据，包含包名和表结构
-	templateData := TemplateData{
-		DaoPackageName: getPackageName(g.configger.GenerateOption.Package.DaoPackage),
-		PoPackageName:  getPackageName(g.configger.GenerateOption.Package.PoPackage),
-		DtoPackageName: getPackageName(g.configger.GenerateOption.Package.DtoPackage),
-		Schemas:        schemas,
-	}
+	// 生成文件路径（输出路径已在配置解析时展开 ~ 符号）
+	filePath := filepath.Join(g.configger.GenerateOption.OutputPath, packagePath, outputFileName)
 
 	// 先将模板执行结果写入缓冲区
 	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, templateData)
+	err = tmpl.Execute(&buf, g.templateData(packagePath, schemas))
 	if err != nil {
-		return fmt.Errorf("执行 DAO 模板失败: %w", err)
+		return fmt.Errorf("执行 %s 模板失败: %w", artifact.Kind, err)
 	}
 
-	// 使用 go/format 格式化代码
-	formattedCode, err := format.Source(buf.Bytes())
-	if err != nil {
-		// 如果格式化失败，记录警告但仍然写入未格式化的代码
-		log.Printf("警告: 格式化 DAO 代码失败: %v，将写入未格式化的代码\n", err)
-		formattedCode = buf.Bytes()
-	}
-
-	// 创建输出文件并写入格式化后的代码
-	err = os.WriteFile(filePath, formattedCode, 0644)
-	if err != nil {
-		return fmt.Errorf("写入 DAO 输出文件失败: %w", err)
+	// 暂存生成的代码，所有文件通过检查后由 WriteFiles 统一写入
+	// 按模板目录生成的文件（如工具代码）与具体的表无关，出错时不列出表名
+	tables := schemas
+	if artifact.scope() == ScopeTemplates {
+		tables = nil
 	}
-
-	log.Printf("成功生成 DAO 文件: %s\n", filePath)
+	g.addFile(artifact.Kind, templateSource, tables, filePath, buf.Bytes())
 	return nil
 }
 
-// GenerateAllTools 生成所有工具文件
-// 返回:
-//   - error: 生成过程中的错误
-func (g *Generator) GenerateAllTools() (err error) {
-	// 从嵌入的文件系统中读取工具模板目录
-	entries, err := templateFS.ReadDir(g.toolTemplateDir)
-	if err != nil {
-		return fmt.Errorf("读取嵌入式工具模板目录失败: %w", err)
-	}
-
-	// 遍历所有模板文件
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		// 只处理 .template 文件
-		templateFileName := entry.Name()
-		if !strings.HasSuffix(templateFileName, ".template") {
-			continue
-		}
-
-		// 生成输出文件名（将 .template 替换为 .go）
-		outputFileName := strings.TrimSuffix(templateFileName, ".template") + ".go"
-
-		// 生成工具文件
-		err = g.GenerateTool(templateFileName, outputFileName)
-		if err != nil {
-			return fmt.Errorf("生成工具文件 %s 失败: %w", outputFileName, err)
-		}
+// templateData 准备传递给模板的数据，包含各包的包名、导入路径和表结构
+// packagePath 为生成的文件所在的包
+func (g *Generator) templateData(packagePath string, schemas []model.Schema) TemplateData {
+	return TemplateData{
+		PackageName:    getPackageName(packagePath),
+		PoPackageName:  getPackageName(g.packagePath(ArtifactPo)),
+		DtoPackageName: getPackageName(g.packagePath(ArtifactDto)),
+		VoPackageName:  getPackageName(g.packagePath(ArtifactVo)),
+		DaoPackageName: getPackageName(g.packagePath(ArtifactDao)),
+		Dialect:        g.dialect(),
+		CrudOnlyIdx:    g.configger.GenerateOption.CrudOnlyIdx,
+		PoImportPath:   g.importPath(ArtifactPo),
+		DtoImportPath:  g.importPath(ArtifactDto),
+		VoImportPath:   g.importPath(ArtifactVo),
+		DaoImportPath:  g.importPath(ArtifactDao),
+		ToolImportPath: g.importPath(ArtifactTool),
+		Imports:        g.imports(schemas),
+		Schemas:        schemas,
 	}
-
-	return nil
 }
 
 // getPackageName 从路径中提取包名（取路径的最后一段）
@@ -449,92 +322,4 @@ func getPackageName(path string) string {
 		return parts[len(parts)-1]
 	}
 	return "model" // 默认返回 model
-}
-
-// GenerateVO 生成 VO 文件
-// 参数:
-//   - schemas: 表结构列表
-//   - outputFileName: 输出文件名
-//
-// 返回:
-//   - error: 生成过程中的错误
-func (g *Generator) GenerateVO(schemas []model.Schema, outputFileName string) (err error) {
-	// 从嵌入的文件系统中读取 VO 模板文件
-	tmplContent, err := templateFS.ReadFile(g.voTemplatePath)
-	if err != nil {
-		return fmt.Errorf("读取嵌入式 VO 模板文件失败: %w", err)
-	}
-
-	// 创建模板并注册函数
-	tmpl, err := template.New("vo").Funcs(template.FuncMap{
-		"ToPascalCase":    ToPascalCase,
-		"ToCamelCase":     ToCamelCase,
-		"ToSafeParamName": ToSafeParamName,
-		"TrimPointer":     TrimPointer,
-		"GetGoType":       GetGoType,
-	}).Parse(string(tmplContent))
-	if err != nil {
-		return fmt.Errorf("解析 VO 模板失败: %w", err)
-	}
-
-	// 从配置中获取输出路径（已在配置解析时展开 ~ 符号）
-	outputPath := filepath.Join(g.configger.GenerateOption.OutputPath, g.configger.GenerateOption.Package.VoPackage)
-
-	// 确保输出目录存在
-	if err = os.MkdirAll(outputPath, 0755); err != nil {
-		return fmt.Errorf("创建 VO 输出目录失败: %w", err)
-	}
-
-	// 生成文件路径
-	filePath := filepath.Join(outputPath, outputFileName)
-
-	// 准备模板数据，包含包名和表结构
-	templateData := TemplateData{
-		DaoPackageName: getPackageName(g.configger.GenerateOption.Package.DaoPackage),
-		PoPackageName:  getPackageName(g.configger.GenerateOption.Package.PoPackage),
-		DtoPackageName: getPackageName(g.configger.GenerateOption.Package.DtoPackage),
-		VoPackageName:  getPackageName(g.configger.GenerateOption.Package.VoPackage),
-		Schemas:        schemas,
-	}
-
-	// 先将模板执行结果写入缓冲区
-	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, templateData)
-	if err != nil {
-		return fmt.Errorf("执行模板失败: %w", err)
-	}
-
-	// 使用 go/format 格式化代码
-	formattedCode, err := format.Source(buf.Bytes())
-	if err != nil {
-		// 如果格式化失败，记录警告但仍然写入未格式化的代码
-		log.Printf("警告: 格式化代码失败: %v，将写入未格式化的代码\n", err)
-		formattedCode = buf.Bytes()
-	}
-
-	// 创建输出文件并写入格式化后的代码
-	err = os.WriteFile(filePath, formattedCode, 0644)
-	if err != nil {
-		return fmt.Errorf("写入输出文件失败: %w", err)
-	}
-
-	log.Printf("成功生成文件: %s\n", filePath)
-	return nil
-}
-
-// GenerateVOOneByOne 根据模板生成 VO 代码，每个表生成一个文件
-// 参数:
-//   - schemas: 表结构列表
-//
-// 返回:
-//   - error: 生成过程中的错误
-func (g *Generator) GenerateVOOneByOne(schemas []model.Schema) (err error) {
-	for _, schema := range schemas {
-		fileName := fmt.Sprintf("%s_vo.go", schema.Name)
-		err = g.GenerateVO([]model.Schema{schema}, fileName)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
 }
\ No newline at end of file
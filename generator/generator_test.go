@@ -0,0 +1,276 @@
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/model"
+	schemaparser "github.com/LingoJack/model_infrax/parser"
+)
+
+// newTestGenerator 把建表语句写入 SQL 文件，以 statement 模式解析表结构，并按 app.Run 的顺序检查结构体名、推导关联关系、覆盖类型和枚举类型
+// builder 只需设置输出路径和需要测试的生成选项
+func newTestGenerator(t *testing.T, sql string, builder *config.ConfiggerBuilder) (*Generator, []model.Schema) {
+	t.Helper()
+	sqlFilePath := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(sqlFilePath, []byte(sql), 0o644); err != nil {
+		t.Fatalf("写入建表语句失败: %v", err)
+	}
+	cfg, err := builder.StatementMode(sqlFilePath).AllTables().Build()
+	if err != nil {
+		t.Fatalf("构建配置失败: %v", err)
+	}
+	statementParser, err := schemaparser.NewStatementParser(cfg)
+	if err != nil {
+		t.Fatalf("初始化SQL文件解析器失败: %v", err)
+	}
+	schemas, err := statementParser.Parse()
+	if err != nil {
+		t.Fatalf("解析建表语句失败: %v", err)
+	}
+	schemas = statementParser.FilterTables(schemas)
+
+	g := NewGenerator(cfg)
+	if err = g.CheckEntityNames(schemas); err != nil {
+		t.Fatalf("检查结构体名失败: %v", err)
+	}
+	schemas = g.ResolveRelations(schemas)
+	if schemas, err = g.ApplyTypeOverrides(schemas); err != nil {
+		t.Fatalf("覆盖列的 Go 类型失败: %v", err)
+	}
+	schemas = g.ResolveEnumTypes(schemas)
+	return g, schemas
+}
+
+// renderArtifacts 按模板集中声明的顺序生成全部产物，返回暂存的代码（相对 output_path 的路径 -> 代码），不写入文件
+// 暂存的代码保留在生成器中，需要时可以继续调用 WriteFiles
+func renderArtifacts(t *testing.T, g *Generator, schemas []model.Schema) map[string]string {
+	t.Helper()
+	for _, artifact := range g.Artifacts() {
+		if err := g.GenerateArtifact(artifact, schemas); err != nil {
+			t.Fatalf("生成 %s 代码失败: %v", artifact.Kind, err)
+		}
+	}
+	files := make(map[string]string)
+	for _, file := range g.files {
+		if file.formatErr != nil {
+			t.Errorf("生成的文件不是合法的 Go 代码 [%s]: %v", g.relPath(file.path), file.formatErr)
+		}
+		files[filepath.ToSlash(g.relPath(file.path))] = string(file.content)
+	}
+	return files
+}
+
+// assertContains 检查生成的文件包含每一段预期的内容
+func assertContains(t *testing.T, files map[string]string, expectations map[string][]string) {
+	t.Helper()
+	for file, expectedList := range expectations {
+		content, ok := files[file]
+		if !ok {
+			t.Errorf("没有生成文件: %s", file)
+			continue
+		}
+		for _, expected := range expectedList {
+			if !strings.Contains(content, expected) {
+				t.Errorf("生成的文件缺少内容 [%s]: %s\n%s", file, expected, content)
+			}
+		}
+	}
+}
+
+// assertNotContains 检查生成的文件不包含任何一段内容
+func assertNotContains(t *testing.T, files map[string]string, unexpectations map[string][]string) {
+	t.Helper()
+	for file, unexpectedList := range unexpectations {
+		for _, unexpected := range unexpectedList {
+			if strings.Contains(files[file], unexpected) {
+				t.Errorf("生成的文件不应包含内容 [%s]: %s\n%s", file, unexpected, files[file])
+			}
+		}
+	}
+}
+
+// funcDeclCount 统计生成的文件中每个函数、方法的声明次数
+func funcDeclCount(t *testing.T, code string) map[string]int {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, 0)
+	if err != nil {
+		t.Fatalf("解析生成的代码失败: %v", err)
+	}
+	count := make(map[string]int)
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+			count[funcDecl.Name.Name]++
+		}
+	}
+	return count
+}
+
+// captureLogs 在测试期间收集日志，用于检查类型检查等只打印日志的结果
+func captureLogs(t *testing.T) *strings.Builder {
+	t.Helper()
+	var logs strings.Builder
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &logs
+}
+
+// newOutputModule 创建一个依赖与本仓库相同的模块（example.com/app），生成到其中的代码可以加载 gorm 等依赖，完整地进行类型检查
+func newOutputModule(t *testing.T) (outputPath string) {
+	t.Helper()
+	moduleDir := t.TempDir()
+	goMod, err := os.ReadFile(filepath.Join("..", "go.mod"))
+	if err != nil {
+		t.Fatalf("读取 go.mod 失败: %v", err)
+	}
+	goMod = regexp.MustCompile(`(?m)^module .*$`).ReplaceAll(goMod, []byte("module example.com/app"))
+	goSum, err := os.ReadFile(filepath.Join("..", "go.sum"))
+	if err != nil {
+		t.Fatalf("读取 go.sum 失败: %v", err)
+	}
+	if err = os.WriteFile(filepath.Join(moduleDir, "go.mod"), goMod, 0o644); err != nil {
+		t.Fatalf("写入 go.mod 失败: %v", err)
+	}
+	if err = os.WriteFile(filepath.Join(moduleDir, "go.sum"), goSum, 0o644); err != nil {
+		t.Fatalf("写入 go.sum 失败: %v", err)
+	}
+	return filepath.Join(moduleDir, "model")
+}
+
+// TestGenerateCompositeUniqueIndex 联合唯一索引，以及与其列相同的普通索引，只生成一组方法
+func TestGenerateCompositeUniqueIndex(t *testing.T) {
+	// 生成到可以加载 gorm 的模块中，完整地进行类型检查，重复声明的方法会导致生成失败
+	outputPath := newOutputModule(t)
+	g, schemas := newTestGenerator(t, `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  tenant_id bigint NOT NULL,
+  name varchar(64) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_tenant_name (tenant_id, name),
+  KEY idx_tenant_name (tenant_id, name),
+  KEY idx_tenant (tenant_id),
+  KEY idx_id (id)
+);`, config.NewBuilder().OutputPath(outputPath))
+	logs := captureLogs(t)
+
+	files := renderArtifacts(t, g, schemas)
+	if err := g.WriteFiles(); err != nil {
+		t.Fatalf("生成代码失败: %v", err)
+	}
+	if !strings.Contains(logs.String(), "类型检查通过") {
+		t.Fatalf("生成的代码应完整地通过类型检查:\n%s", logs.String())
+	}
+
+	methodCount := funcDeclCount(t, files["dao/t_user_dao.go"])
+	for _, method := range []string{"SelectById", "SelectByTenantIdAndName", "DeleteByTenantIdAndName", "SelectByTenantId", "SelectByTenantIdList"} {
+		if methodCount[method] != 1 {
+			t.Errorf("方法 %s 应生成且只生成一次，实际 %d 次", method, methodCount[method])
+		}
+	}
+}
+
+// TestGenerateCrudOnlyIdx 开启 crud_only_idx 后只为索引列生成查询条件，且不生成模糊查询
+func TestGenerateCrudOnlyIdx(t *testing.T) {
+	g, schemas := newTestGenerator(t, `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_name varchar(64) NOT NULL,
+  remark varchar(255),
+  PRIMARY KEY (id),
+  KEY idx_user_name (user_name)
+);`, config.NewBuilder().OutputPath(t.TempDir()).CrudOnlyIdx(true))
+
+	files := renderArtifacts(t, g, schemas)
+	assertContains(t, files, map[string][]string{
+		"dto/t_user_dto.go": {"Id ", "IdList ", "UserName ", "UserNameList "},
+		"dao/t_user_dao.go": {"user_name"},
+		"po/t_user.go":      {"Remark "},
+	})
+	assertNotContains(t, files, map[string][]string{
+		"dto/t_user_dto.go": {"Remark", "Fuzzy"},
+		"dao/t_user_dao.go": {"dto.Remark", "LIKE"},
+	})
+}
+
+// TestGenerateAnnotations 由表、列注释中的注解控制生成的结构体名、字段名、类型和字段
+func TestGenerateAnnotations(t *testing.T) {
+	g, schemas := newTestGenerator(t, `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  name varchar(64) NOT NULL COMMENT '姓名 @name(FullName)',
+  password varchar(128) NOT NULL COMMENT '密码 @sensitive',
+  created_at datetime NOT NULL,
+  PRIMARY KEY (id)
+) COMMENT='用户表 @name(Member)';
+CREATE TABLE t_task (
+  id bigint NOT NULL AUTO_INCREMENT,
+  executor varchar(16) NOT NULL COMMENT '执行器 @enum(robot,brain)',
+  extra json DEFAULT NULL COMMENT '扩展信息 @json(encoding/json.RawMessage)',
+  legacy varchar(32) DEFAULT NULL COMMENT '@ignore',
+  created_at datetime NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE TABLE t_tmp (id bigint) COMMENT='@ignore';`, config.NewBuilder().OutputPath(t.TempDir()))
+
+	files := renderArtifacts(t, g, schemas)
+	assertContains(t, files, map[string][]string{
+		"po/member.go":      {"type Member struct", "// Member 用户表\n", "\tFullName ", `json:"-"`},
+		"vo/member_vo.go":   {"type MemberVo struct", "\tFullName "},
+		"po/t_task.go":      {"type TTaskExecutor string", `TTaskExecutorRobot TTaskExecutor = "robot"`, "serializer:json;comment:扩展信息;", " *json.RawMessage "},
+		"dto/t_task_dto.go": {" po.TTaskExecutor "},
+	})
+	assertNotContains(t, files, map[string][]string{
+		"vo/member_vo.go":   {"Password"},
+		"po/t_task.go":      {"Legacy", "@ignore"},
+		"dto/t_task_dto.go": {"Extra"},
+	})
+	if _, ok := files["po/t_tmp.go"]; ok {
+		t.Errorf("标记 @ignore 的表不应生成代码")
+	}
+}
+
+// TestGenerateDuplicateIndexes 列相同的普通索引、与联合主键列相同的普通索引、与单列主键列相同的唯一索引，只生成一组方法
+func TestGenerateDuplicateIndexes(t *testing.T) {
+	sql := `CREATE TABLE t_member (
+  tenant_id bigint NOT NULL,
+  user_id bigint NOT NULL,
+  PRIMARY KEY (tenant_id, user_id),
+  KEY idx_tenant_user (tenant_id, user_id),
+  KEY idx_tenant_user_dup (tenant_id, user_id),
+  KEY idx_user (user_id),
+  KEY idx_user_dup (user_id)
+);
+CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  name varchar(64) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_id (id),
+  UNIQUE KEY uk_name (name),
+  UNIQUE KEY uk_name_dup (name),
+  KEY idx_name (name)
+);`
+	for _, framework := range []string{"", "itea-go"} {
+		t.Run("framework="+framework, func(t *testing.T) {
+			g, schemas := newTestGenerator(t, sql, config.NewBuilder().OutputPath(t.TempDir()).UseFramework(framework))
+			files := renderArtifacts(t, g, schemas)
+
+			for file, methods := range map[string][]string{
+				"dao/t_member_dao.go": {"SelectByTenantIdAndUserId", "DeleteByTenantIdAndUserId", "SelectByUserId", "SelectByUserIdList"},
+				"dao/t_user_dao.go":   {"SelectById", "SelectByIdList", "DeleteById", "SelectByName", "SelectByNameList", "DeleteByName"},
+			} {
+				methodCount := funcDeclCount(t, files[file])
+				for _, method := range methods {
+					if methodCount[method] != 1 {
+						t.Errorf("%s: 方法 %s 应生成且只生成一次，实际 %d 次", file, method, methodCount[method])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+)
+
+// cleanImports 删除生成的代码中重复和未被引用的导入（匿名导入、点导入除外）
+// 模板按最大可能的需要导入包（如 PO 中的 time、DTO 中的 PO 包），类型覆盖的导入按表统一添加，
+// 可能与模板自带的导入重复（如 encoding/json），也不是每种文件都会用到所有列（如 DAO 只用到主键、索引列）
+// 无法确定包名的导入（见 knownPackageName）即使看起来没有被引用也保留，误删会导致生成的代码无法编译
+// 代码无法解析时原样返回，交给后续的格式化步骤报告错误
+func cleanImports(src []byte) []byte {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return src
+	}
+
+	// 解析器会把标识符解析到文件内的声明，导入的包名不在文件作用域中，引用包的标识符 Obj 为 nil
+	// 与包名同名的局部变量、参数（如 json := ...; json.Marshal）解析到对应的声明，不算引用了包
+	usedPackages := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selector.X.(*ast.Ident); ok && ident.Obj == nil {
+				usedPackages[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	// isUnused 判断导入是否确定未被引用
+	isUnused := func(importSpec *ast.ImportSpec) bool {
+		if importSpec.Name != nil {
+			name := importSpec.Name.Name
+			return name != "_" && name != "." && !usedPackages[name]
+		}
+		importPath, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil {
+			return false
+		}
+		name, known := knownPackageName(importPath)
+		return known && !usedPackages[name]
+	}
+
+	// 模板中每个导入单独一行，直接删除源码中对应的行，保持其余导入的顺序和分组
+	// （修改语法树后用 format.Node 输出时，剩余导入仍按原位置排列，会打乱分组）
+	seen := make(map[string]bool)
+	removedLines := make(map[int]bool)
+	for _, importSpec := range file.Imports {
+		key := importSpec.Path.Value
+		if importSpec.Name != nil {
+			key = importSpec.Name.Name + " " + key
+		}
+		if seen[key] || isUnused(importSpec) {
+			removedLines[fset.Position(importSpec.Pos()).Line] = true
+			continue
+		}
+		seen[key] = true
+	}
+	if len(removedLines) == 0 {
+		return src
+	}
+
+	var buf bytes.Buffer
+	for i, line := range bytes.SplitAfter(src, []byte("\n")) {
+		if !removedLines[i+1] {
+			buf.Write(line)
+		}
+	}
+	return buf.Bytes()
+}
+
+// knownPackageName 返回没有别名的导入在代码中使用的包名，只有能确定包名时 known 为 true
+// 最后一段路径（跳过主版本号后缀）是合法标识符时，按惯例（与 goimports 相同）以其为包名；
+// 最后一段不是合法标识符时包名由包自己声明，无法从路径推断
+// 示例:
+//   - encoding/json -> json, true
+//   - github.com/jackc/pgx/v5 -> pgx, true
+//   - gopkg.in/yaml.v3 -> "", false（包名为 yaml）
+//   - github.com/mattn/go-sqlite3 -> "", false（包名为 sqlite3）
+func knownPackageName(importPath string) (name string, known bool) {
+	name = importPackageName(importPath)
+	if !token.IsIdentifier(name) {
+		return "", false
+	}
+	return name, true
+}
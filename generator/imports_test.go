@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCleanImports(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		imports  string
+		body     string
+		expected string
+	}{
+		{"删除未引用和重复的导入", `"encoding/json"` + "\n" + `"time"` + "\n" + `"time"`, "var _ = time.Now", `"time"`},
+		{"包名取跳过主版本号后的最后一段", `"github.com/jackc/pgx/v5"` + "\n" + `"github.com/jackc/pgx/v5/pgtype"`, "var _ pgtype.Text", `"github.com/jackc/pgx/v5/pgtype"`},
+		{"别名按别名判断", `js "encoding/json"` + "\n" + `t "time"`, "var _ = js.Marshal", `js "encoding/json"`},
+		{"匿名导入和点导入保留", `_ "embed"` + "\n" + `. "strings"`, "", `_ "embed"` + "\n" + `. "strings"`},
+		{"与包名同名的局部变量不算引用", `"encoding/json"` + "\n" + `"time"`, "func f(time int) string {\n\tjson := struct{ Marshal string }{}\n\treturn json.Marshal\n}\n\nvar _ = time.Now", `"time"`},
+		{"与包名同名的参数不算引用", `"encoding/json"` + "\n" + `"time"`, "func f(json struct{ Marshal string }) string { return json.Marshal }\n\nvar _ = time.Now", `"time"`},
+		// 包名与路径最后一段不同，无法确定是否被引用，保留
+		{"无法确定包名的导入保留", `"gopkg.in/yaml.v3"` + "\n" + `"github.com/mattn/go-sqlite3"`, "var _ = yaml.Marshal", `"gopkg.in/yaml.v3"` + "\n" + `"github.com/mattn/go-sqlite3"`},
+	} {
+		src := fmt.Sprintf("package po\n\nimport (\n%s\n)\n\n%s\n", testCase.imports, testCase.body)
+		expected := fmt.Sprintf("package po\n\nimport (\n%s\n)\n\n%s\n", testCase.expected, testCase.body)
+		if actual := string(cleanImports([]byte(src))); actual != expected {
+			t.Errorf("%s:\n%s\n期望:\n%s", testCase.name, actual, expected)
+		}
+	}
+}
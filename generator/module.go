@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// resolveOutputImportPath 返回 output_path 对应的 Go 导入路径，生成的代码通过它导入 po、dto 等其他包
+//   - 配置了 module_path 时直接使用
+//   - 否则从 output_path 向上查找 go.mod，按 output_path 相对 go.mod 所在目录的路径拼接模块路径
+//
+// 示例: go.mod 位于 /work/app，模块路径为 github.com/foo/app，output_path 为 /work/app/internal/model
+// -> github.com/foo/app/internal/model
+func resolveOutputImportPath(modulePath, outputPath string) (string, error) {
+	if modulePath != "" {
+		return strings.TrimSuffix(modulePath, "/"), nil
+	}
+
+	outputDir, err := filepath.Abs(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("获取输出路径的绝对路径失败: %w", err)
+	}
+	for dir := outputDir; ; dir = filepath.Dir(dir) {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			module, err := parseModulePath(data)
+			if err != nil {
+				return "", fmt.Errorf("解析 %s 失败: %w", filepath.Join(dir, "go.mod"), err)
+			}
+			relPath, err := filepath.Rel(dir, outputDir)
+			if err != nil {
+				return "", fmt.Errorf("计算输出路径相对 go.mod 的路径失败: %w", err)
+			}
+			return path.Join(module, filepath.ToSlash(relPath)), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("读取 go.mod 失败: %w", err)
+		}
+		if filepath.Dir(dir) == dir {
+			return "", nil
+		}
+	}
+}
+
+// parseModulePath 从 go.mod 的内容中解析 module 指令声明的模块路径
+func parseModulePath(data []byte) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if comment := strings.Index(line, "//"); comment >= 0 {
+			line = strings.TrimSpace(line[:comment])
+		}
+		module, ok := strings.CutPrefix(line, "module")
+		if !ok || module == "" || (module[0] != ' ' && module[0] != '\t' && module[0] != '"') {
+			continue
+		}
+		module = strings.TrimSpace(module)
+		if unquoted, err := strconv.Unquote(module); err == nil {
+			module = unquoted
+		}
+		if module != "" {
+			return module, nil
+		}
+	}
+	return "", fmt.Errorf("缺少 module 指令")
+}
+
+// importPath 返回产物所在包的完整导入路径，无法确定 output_path 的导入路径时返回空字符串
+func (g *Generator) importPath(kind string) string {
+	if g.outputImportPath == "" {
+		return ""
+	}
+	return path.Join(g.outputImportPath, filepath.ToSlash(g.packagePath(kind)))
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+)
+
+// TestResolveOutputImportPath 按 output_path 所在的 go.mod 生成跨包引用的导入，并删除未使用的导入
+func TestResolveOutputImportPath(t *testing.T) {
+	const sql = `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_name varchar(64) NOT NULL,
+  created_at datetime NOT NULL,
+  PRIMARY KEY (id),
+  KEY idx_created_at (created_at)
+);`
+	moduleDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte("module example.com/app // 示例\n\ngo 1.22\n"), 0o644); err != nil {
+		t.Fatalf("写入 go.mod 失败: %v", err)
+	}
+	g, schemas := newTestGenerator(t, sql, config.NewBuilder().OutputPath(filepath.Join(moduleDir, "internal", "model")))
+	files := renderArtifacts(t, g, schemas)
+
+	for file, expectedImports := range map[string][]string{
+		"dao/t_user_dao.go": {"context", "time", "gorm.io/gorm", "example.com/app/internal/model/po", "example.com/app/internal/model/dto"},
+		"po/t_user.go":      {"time"},
+		"dto/t_user_dto.go": {"time"},
+	} {
+		parsed, err := parser.ParseFile(token.NewFileSet(), file, files[file], parser.ImportsOnly)
+		if err != nil {
+			t.Fatalf("解析生成的文件失败 [%s]: %v", file, err)
+		}
+		imports := make(map[string]bool)
+		for _, importSpec := range parsed.Imports {
+			importPath, _ := strconv.Unquote(importSpec.Path.Value)
+			imports[importPath] = true
+		}
+		for _, expected := range expectedImports {
+			if !imports[expected] {
+				t.Errorf("生成的文件缺少导入 [%s]: %s, 实际: %v", file, expected, imports)
+			}
+		}
+		// DTO 中没有引用 PO 包中的类型（如枚举类型），不应导入 PO 包
+		if parsed.Name.Name == "dto" && imports["example.com/app/internal/model/po"] {
+			t.Errorf("DTO 不应导入未使用的 PO 包")
+		}
+	}
+
+	// 配置 module_path 时直接作为 output_path 的导入路径
+	g, schemas = newTestGenerator(t, sql, config.NewBuilder().OutputPath(t.TempDir()).ModulePath("github.com/foo/bar/gen/"))
+	assertContains(t, renderArtifacts(t, g, schemas), map[string][]string{
+		"dao/t_user_dao.go": {`"github.com/foo/bar/gen/po"`},
+	})
+}
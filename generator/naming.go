@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/iancoleman/strcase"
+)
+
+// defaultTableNamePrefixes 开启 ignore_table_name_prefix 但未配置任何规则时默认去除的前缀
+var defaultTableNamePrefixes = []string{"t_", "tb_", "tbl_"}
+
+// compileTableNameRegexps 编译 table_name_trim.regexps，正则表达式已在加载配置时校验，这里跳过不合法的表达式
+func compileTableNameRegexps(patterns []string) []*regexp.Regexp {
+	var regexps []*regexp.Regexp
+	for _, pattern := range patterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			regexps = append(regexps, re)
+		}
+	}
+	return regexps
+}
+
+// entityBaseName 返回表对应的实体名（下划线风格），用于生成类型名、文件名和 DAO 名
+// 表注释 @name 指定了结构体名时使用其下划线形式；未开启 ignore_table_name_prefix 时直接返回表名；去除前缀、后缀后为空时保留原表名
+func (g *Generator) entityBaseName(tableName string) string {
+	if entityName, ok := g.entityNames[tableName]; ok {
+		return strcase.ToSnake(entityName)
+	}
+	option := g.configger.GenerateOption
+	if !option.IgnoreTableNamePrefix {
+		return tableName
+	}
+
+	trim := option.TableNameTrim
+	if trim.IsEmpty() {
+		trim.Prefixes = defaultTableNamePrefixes
+	}
+
+	name := tableName
+	for _, prefix := range trim.Prefixes {
+		if strings.HasPrefix(name, prefix) {
+			name = strings.TrimPrefix(name, prefix)
+			break
+		}
+	}
+	for _, suffix := range trim.Suffixes {
+		if strings.HasSuffix(name, suffix) {
+			name = strings.TrimSuffix(name, suffix)
+			break
+		}
+	}
+	for _, re := range g.tableNameRegexps {
+		name = re.ReplaceAllString(name, "")
+	}
+
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return tableName
+	}
+	return name
+}
+
+// EntityName 返回表对应的结构体名（PascalCase），PO、DTO、VO、DAO 的类型名都以它为基础
+// 表注释 @name 指定的结构体名优先
+func (g *Generator) EntityName(tableName string) string {
+	if entityName, ok := g.entityNames[tableName]; ok {
+		return entityName
+	}
+	return ToPascalCase(g.entityBaseName(tableName))
+}
+
+// FieldName 返回列对应的结构体字段名，列注释 @name 指定的字段名优先，否则为列名的 PascalCase 形式
+func FieldName(column model.Column) string {
+	if column.FieldName != "" {
+		return column.FieldName
+	}
+	return ToPascalCase(column.ColumnName)
+}
+
+// CheckEntityNames 记录表注释 @name 指定的结构体名，并检查是否有多张表对应同一个结构体名
+// 例如 t_user 和 tb_user 去除前缀后都会生成 User，生成的代码无法编译，需要调整去除规则、表过滤规则或 @name 注解
+func (g *Generator) CheckEntityNames(schemas []model.Schema) error {
+	g.entityNames = make(map[string]string)
+	for _, schema := range schemas {
+		if schema.EntityName != "" {
+			g.entityNames[schema.Name] = schema.EntityName
+		}
+	}
+
+	entityName2TableName := make(map[string]string)
+	for _, schema := range schemas {
+		entityName := g.EntityName(schema.Name)
+		if tableName, exists := entityName2TableName[entityName]; exists {
+			return fmt.Errorf("表 %s 和 %s 都对应结构体 %s，请调整 table_name_trim 配置或表注释中的 @name", tableName, schema.Name, entityName)
+		}
+		entityName2TableName[entityName] = schema.Name
+		if entityName != ToPascalCase(schema.Name) {
+			log.Printf("🏷️ 表 %s 生成的结构体名为 %s", schema.Name, entityName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/model"
+)
+
+// TestTrimTableName 去除表名前缀、后缀后生成类型名和文件名，TableName() 仍返回真实表名
+func TestTrimTableName(t *testing.T) {
+	g, schemas := newTestGenerator(t, `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_name varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE TABLE tb_order_tab (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_id bigint NOT NULL,
+  PRIMARY KEY (id),
+  FOREIGN KEY (user_id) REFERENCES t_user (id)
+);`, config.NewBuilder().OutputPath(t.TempDir()).TableNamePrefixes("t_", "tb_").TableNameSuffixes("_tab"))
+
+	assertContains(t, renderArtifacts(t, g, schemas), map[string][]string{
+		"po/user.go":       {"type User struct", `return "t_user"`, "OrderList []*Order"},
+		"po/order.go":      {"type Order struct", `return "tb_order_tab"`, "*User"},
+		"dto/user_dto.go":  {"type UserDto struct"},
+		"vo/order_vo.go":   {"type OrderVo struct"},
+		"dao/order_dao.go": {"type OrderDao struct", "func (dao *OrderDao) SelectByIdWithUser("},
+	})
+}
+
+// TestCheckEntityNames 去除前缀后多张表对应同一个结构体名时应返回错误
+func TestCheckEntityNames(t *testing.T) {
+	cfg := config.NewBuilder().StatementMode("schema.sql").AllTables().OutputPath(t.TempDir()).IgnoreTableNamePrefix(true).MustBuild()
+	g := NewGenerator(cfg)
+	if err := g.CheckEntityNames([]model.Schema{{Name: "t_user"}, {Name: "tb_user"}}); err == nil || !strings.Contains(err.Error(), "User") {
+		t.Errorf("结构体名冲突时应返回错误: %v", err)
+	}
+	if err := g.CheckEntityNames([]model.Schema{{Name: "t_user"}, {Name: "t_order"}}); err != nil {
+		t.Errorf("结构体名不冲突时不应返回错误: %v", err)
+	}
+}
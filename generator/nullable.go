@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/samber/lo"
+)
+
+// sqlNullFields 有对应 sql.Null* 类型的基础类型，值为类型名去掉 sql.Null 前缀的部分，也是存放值的字段名
+// 例如 string -> sql.NullString，值在 String 字段中
+var sqlNullFields = map[string]string{
+	"string":    "String",
+	"int64":     "Int64",
+	"int32":     "Int32",
+	"int16":     "Int16",
+	"uint8":     "Byte",
+	"float64":   "Float64",
+	"bool":      "Bool",
+	"time.Time": "Time",
+}
+
+// sqlNullBaseTypes sqlNullFields 的反向映射，sql.Null* 类型去掉前缀后的部分 -> 基础类型
+var sqlNullBaseTypes = lo.Invert(sqlNullFields)
+
+// nullableStyleGoType 将可空列默认的指针类型转换为 nullable_style 指定的风格，非指针类型（如 []byte）保持不变
+// 示例（sql_null）:
+//   - *string -> sql.NullString
+//   - *uint32 -> sql.Null[uint32]（没有对应的 sql.Null* 类型）
+func nullableStyleGoType(style string, goType string) string {
+	if !IsPointer(goType) {
+		return goType
+	}
+	baseType := TrimPointer(goType)
+	switch style {
+	case config.NullableStyleSqlNull:
+		if field, ok := sqlNullFields[baseType]; ok {
+			return "sql.Null" + field
+		}
+		return "sql.Null[" + baseType + "]"
+	case config.NullableStyleGeneric:
+		return "sql.Null[" + baseType + "]"
+	default:
+		return goType
+	}
+}
+
+// IsNullWrapper 判断是否为 sql.NullString、sql.Null[T] 等通过 Valid 字段表示 NULL 的类型
+func IsNullWrapper(goType string) bool {
+	return strings.HasPrefix(goType, "sql.Null")
+}
+
+// NullBaseType 返回可空类型中值的类型，用于 With*Value 便捷方法的参数、DTO 中的范围和 IN 查询字段
+// 示例:
+//   - *string -> string
+//   - sql.NullTime -> time.Time
+//   - sql.Null[uint32] -> uint32
+func NullBaseType(goType string) string {
+	if generic, ok := strings.CutPrefix(goType, "sql.Null["); ok {
+		return strings.TrimSuffix(generic, "]")
+	}
+	if baseType, ok := sqlNullBaseTypes[strings.TrimPrefix(goType, "sql.Null")]; ok && IsNullWrapper(goType) {
+		return baseType
+	}
+	return TrimPointer(goType)
+}
+
+// NullValue 返回将值 expr 转换为可空类型的表达式
+// 示例:
+//   - *string, name -> &name
+//   - sql.NullString, name -> sql.NullString{String: name, Valid: true}
+//   - sql.Null[uint32], count -> sql.Null[uint32]{V: count, Valid: true}
+func NullValue(goType string, expr string) string {
+	if !IsNullWrapper(goType) {
+		return "&" + expr
+	}
+	field := "V"
+	if !strings.HasPrefix(goType, "sql.Null[") {
+		field = strings.TrimPrefix(goType, "sql.Null")
+	}
+	return fmt.Sprintf("%s{%s: %s, Valid: true}", goType, field, expr)
+}
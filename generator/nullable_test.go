@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+)
+
+// TestGenerateNullableStyle nullable_style 为 sql_null、generic 时可空列使用 sql.Null 类型，VO 仍使用指针
+// 生成到可以加载 gorm 的模块中，PO、DTO、DAO 中 With*Value、零值判断等分支都经过完整的类型检查
+func TestGenerateNullableStyle(t *testing.T) {
+	const sql = `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  nickname varchar(64),
+  score int unsigned,
+  updated_at datetime,
+  created_at datetime NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+	for style, expectations := range map[string]map[string][]string{
+		config.NullableStyleSqlNull: {
+			"po/t_user.go":      {`"database/sql"`, " sql.NullString ", " sql.Null[uint32] ", " sql.NullTime ", "sql.NullString{String: nickname, Valid: true}"},
+			"dto/t_user_dto.go": {" sql.NullString ", "func (b *TUserDtoBuilder) WithNicknameValue(nickname string)"},
+			"dao/t_user_dao.go": {"if queryDto.Nickname.Valid {", `"nickname LIKE ?"`},
+			"vo/t_user_vo.go":   {" *string ", " *uint32 "},
+		},
+		config.NullableStyleGeneric: {
+			"po/t_user.go":      {`"database/sql"`, " sql.Null[string] ", " sql.Null[uint32] ", " sql.Null[time.Time] ", "sql.Null[string]{V: nickname, Valid: true}"},
+			"dto/t_user_dto.go": {" sql.Null[string] ", "func (b *TUserDtoBuilder) WithNicknameValue(nickname string)", "sql.Null[time.Time]{V: updatedAt, Valid: true}"},
+			"dao/t_user_dao.go": {"if queryDto.Nickname.Valid {", `"nickname LIKE ?"`},
+			"vo/t_user_vo.go":   {" *string ", " *uint32 ", " *time.Time "},
+		},
+	} {
+		t.Run(style, func(t *testing.T) {
+			g, schemas := newTestGenerator(t, sql, config.NewBuilder().OutputPath(newOutputModule(t)).NullableStyle(style))
+			logs := captureLogs(t)
+
+			assertContains(t, renderArtifacts(t, g, schemas), expectations)
+			if err := g.WriteFiles(); err != nil {
+				t.Fatalf("生成代码失败: %v", err)
+			}
+			if !strings.Contains(logs.String(), "类型检查通过") {
+				t.Fatalf("生成的代码应完整地通过类型检查:\n%s", logs.String())
+			}
+		})
+	}
+}
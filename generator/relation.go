@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
+)
+
+// poReservedNames PO 结构体上已有的方法名，关联字段不能与之重名
+var poReservedNames = []string{"TableName", "Jsonify", "JsonifyIndent"}
+
+// ResolveRelations 根据外键推导表之间的关联关系，写入每个表的 Relations
+// 只处理单列外键，且被引用的表也需要参与生成，否则生成的关联字段无法编译:
+//   - 外键所在的表: belongs_to，名称为外键列去掉 _id 后缀（user_id -> user），没有该后缀时使用被引用表的实体名
+//   - 被引用的表: has_many，名称为 <外键所在表的实体名>_list（t_order -> t_order_list，去除 t_ 前缀时为 order_list）
+//
+// 名称与已有的列、方法或其他关联冲突时，追加外键列名加以区分（如 user_by_buyer_id、t_order_by_buyer_id_list）
+func (g *Generator) ResolveRelations(schemas []model.Schema) []model.Schema {
+	schemaIndexMap := make(map[string]int)
+	usedNames := make([]map[string]bool, len(schemas))
+	for i := range schemas {
+		schemas[i].Relations = nil
+		schemaIndexMap[strings.ToLower(schemas[i].Name)] = i
+		usedNames[i] = make(map[string]bool)
+		for _, column := range schemas[i].Columns {
+			usedNames[i][FieldName(column)] = true
+		}
+		for _, name := range poReservedNames {
+			usedNames[i][name] = true
+		}
+	}
+
+	// uniqueName 返回第一个未被占用的名称并将其标记为已占用
+	uniqueName := func(schemaIdx int, candidates ...string) (string, bool) {
+		for _, candidate := range candidates {
+			if fieldName := ToPascalCase(candidate); !usedNames[schemaIdx][fieldName] {
+				usedNames[schemaIdx][fieldName] = true
+				return candidate, true
+			}
+		}
+		return "", false
+	}
+
+	for childIdx := range schemas {
+		child := schemas[childIdx]
+		for _, foreignKey := range child.ForeignKeys {
+			if len(foreignKey.Columns) != 1 || len(foreignKey.ReferencedColumns) != 1 {
+				continue
+			}
+			parentIdx, exists := schemaIndexMap[strings.ToLower(foreignKey.ReferencedTable)]
+			if !exists {
+				continue
+			}
+			parent := schemas[parentIdx]
+			column, found := lo.Find(child.Columns, func(column model.Column) bool {
+				return strings.EqualFold(column.ColumnName, foreignKey.Columns[0])
+			})
+			referenced, referencedFound := lo.Find(parent.Columns, func(column model.Column) bool {
+				return strings.EqualFold(column.ColumnName, foreignKey.ReferencedColumns[0])
+			})
+			if !found || !referencedFound {
+				continue
+			}
+
+			belongsTo := column.ColumnName
+			if trimmed := strings.TrimSuffix(strings.ToLower(belongsTo), "_id"); trimmed != strings.ToLower(belongsTo) && trimmed != "" {
+				belongsTo = belongsTo[:len(trimmed)]
+			} else {
+				belongsTo = g.entityBaseName(parent.Name)
+			}
+			if name, ok := uniqueName(childIdx, belongsTo, belongsTo+"_by_"+column.ColumnName); ok {
+				schemas[childIdx].Relations = append(schemas[childIdx].Relations, model.Relation{
+					Kind:            model.RelationBelongsTo,
+					Name:            name,
+					Table:           parent.Name,
+					ForeignKey:      column.ColumnName,
+					References:      referenced.ColumnName,
+					ForeignKeyField: FieldName(column),
+					ReferencesField: FieldName(referenced),
+				})
+			}
+
+			childName := g.entityBaseName(child.Name)
+			if name, ok := uniqueName(parentIdx, childName+"_list", childName+"_by_"+column.ColumnName+"_list"); ok {
+				schemas[parentIdx].Relations = append(schemas[parentIdx].Relations, model.Relation{
+					Kind:            model.RelationHasMany,
+					Name:            name,
+					Table:           child.Name,
+					ForeignKey:      column.ColumnName,
+					References:      referenced.ColumnName,
+					ForeignKeyField: FieldName(column),
+					ReferencesField: FieldName(referenced),
+				})
+			}
+		}
+	}
+	return schemas
+}
@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+)
+
+// TestResolveRelations 由外键生成 GORM 关联字段和预加载方法
+func TestResolveRelations(t *testing.T) {
+	g, schemas := newTestGenerator(t, `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_name varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE TABLE t_order (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_id bigint NOT NULL,
+  amount decimal(10, 2),
+  PRIMARY KEY (id),
+  CONSTRAINT fk_order_user FOREIGN KEY (user_id) REFERENCES t_user (id)
+);`, config.NewBuilder().OutputPath(t.TempDir()))
+
+	assertContains(t, renderArtifacts(t, g, schemas), map[string][]string{
+		"po/t_order.go":      {"*TUser", `gorm:"foreignKey:UserId;references:Id" json:"user,omitempty"`},
+		"po/t_user.go":       {"[]*TOrder", `gorm:"foreignKey:UserId;references:Id" json:"t_order_list,omitempty"`},
+		"dao/t_order_dao.go": {"func (dao *TOrderDao) SelectByIdWithUser(", `Preload("User")`},
+		"dao/t_user_dao.go":  {"func (dao *TUserDao) SelectByIdWithTOrderList(", `Preload("TOrderList")`},
+	})
+}
@@ -1,124 +1,208 @@
 package generator
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/LingoJack/model_infrax/model"
 	"github.com/LingoJack/model_infrax/tool"
+	"github.com/samber/lo"
 )
 
+// columnType 解析后的数据库列类型
+type columnType struct {
+	base     string // 基础类型名，小写，去掉长度、精度和 unsigned 等修饰（如 int、character varying、timestamp with time zone）
+	length   *int64 // 长度或精度（如 varchar(64) 为 64、decimal(10,2) 为 10、tinyint(1) 为 1），没有时为nil
+	scale    *int64 // 小数位数（如 decimal(10,2) 为 2），没有时为nil
+	unsigned bool   // 是否无符号
+	array    bool   // 是否为 PostgreSQL 数组（如 integer[]）
+}
+
+// parseColumnType 将数据库列类型解析为基础类型、长度、精度和是否无符号
+// 示例:
+//   - bigint(20) unsigned zerofill -> base: bigint, length: 20, unsigned: true
+//   - decimal(10, 2) -> base: decimal, length: 10, scale: 2
+//   - timestamp(3) with time zone -> base: timestamp with time zone, length: 3
+//   - enum('a','b') -> base: enum
+//   - varbinary(64) binary -> base: varbinary, length: 64
+//   - LowCardinality(Nullable(String)) -> base: string
+func parseColumnType(dbType string) columnType {
+	dbType = unwrapClickhouseType(strings.ToLower(strings.TrimSpace(dbType)))
+
+	var parsed columnType
+	for strings.HasSuffix(dbType, "[]") {
+		parsed.array = true
+		dbType = strings.TrimSuffix(dbType, "[]")
+	}
+
+	// 括号中的参数，只解析数值形式的长度、精度（enum 的取值、ClickHouse 的嵌套类型等忽略）
+	if open, closing := strings.Index(dbType, "("), strings.LastIndex(dbType, ")"); open >= 0 && closing > open {
+		args := strings.Split(dbType[open+1:closing], ",")
+		if length, err := strconv.ParseInt(strings.TrimSpace(args[0]), 10, 64); err == nil {
+			parsed.length = &length
+		}
+		if len(args) > 1 {
+			if scale, err := strconv.ParseInt(strings.TrimSpace(args[1]), 10, 64); err == nil {
+				parsed.scale = &scale
+			}
+		}
+		dbType = dbType[:open] + " " + dbType[closing+1:]
+	}
+
+	// 去掉字符集、排序规则（如 varchar(64) character set utf8mb4 collate utf8mb4_bin）
+	words := strings.Fields(dbType)
+	for i, word := range words {
+		if i > 0 && (word == "collate" || word == "charset" || word == "character" && i+1 < len(words) && words[i+1] == "set") {
+			words = words[:i]
+			break
+		}
+	}
+	words = lo.Filter(words, func(word string, i int) bool {
+		switch word {
+		case "unsigned":
+			parsed.unsigned = true
+			return false
+		case "signed", "zerofill":
+			return false
+		case "binary", "ascii", "unicode":
+			// varbinary(64) binary、blob binary 中的 binary 是字符集修饰
+			return i == 0
+		}
+		return true
+	})
+	parsed.base = strings.Join(words, " ")
+	return parsed
+}
+
 // typeMapping 定义了数据库类型到 Go 类型的映射规则
 type typeMapping struct {
 	// 匹配函数：判断数据库类型是否匹配
-	matcher func(dbType string) bool
+	matcher func(t columnType) bool
 	// 非空时的 Go 类型
 	goType string
 	// 可空时的 Go 类型
 	nullableGoType string
+	// 只在这些数据库方言下使用的规则，为空时所有方言通用
+	dialects []string
 }
 
 // typeMappings 定义所有类型映射规则
-// 规则顺序很重要，优先匹配更具体的规则
+// 按解析后的基础类型精确匹配，规则顺序只影响同一基础类型的不同变体（如 tinyint(1) 需在 tinyint 之前，unsigned 需在有符号之前）
+// 同名类型在不同方言中含义不同时按方言区分（如 ClickHouse 的 Int8 为 8 位，PostgreSQL 的 int8 为 64 位）
+// 未匹配的类型（char、varchar、text、json、enum、set、uuid 等）映射为 string
 var typeMappings = []typeMapping{
-	// 1. bigint unsigned - 无符号大整数
-	{
-		matcher: func(dbType string) bool {
-			return strings.Contains(dbType, "bigint") && strings.Contains(dbType, "unsigned")
-		},
-		goType:         "uint64",
-		nullableGoType: "*uint64",
-	},
-	// 2. bigint - 有符号大整数
+	// 0. PostgreSQL 数组（如 integer[]、text[]）- 以数组字面量字符串形式读写
 	{
-		matcher: func(dbType string) bool {
-			return strings.Contains(dbType, "bigint")
+		matcher: func(t columnType) bool {
+			return t.array
 		},
-		goType:         "int64",
-		nullableGoType: "*int64",
+		goType:         "string",
+		nullableGoType: "*string",
 	},
-	// 3. tinyint(1) - 布尔类型
+	// 0.1 ClickHouse 复合类型（Array、Map、Tuple、Nested）及枚举 - 以字符串形式读写
+	dialectTypeMapping("clickhouse", baseTypeMapping("string", "array", "map", "tuple", "nested", "enum8", "enum16")),
+	// 0.2 ClickHouse 定长整数
+	dialectTypeMapping("clickhouse", baseTypeMapping("int8", "int8")),
+	dialectTypeMapping("clickhouse", baseTypeMapping("int16", "int16")),
+	dialectTypeMapping("clickhouse", baseTypeMapping("int32", "int32")),
+	dialectTypeMapping("clickhouse", baseTypeMapping("int64", "int64")),
+	dialectTypeMapping("clickhouse", baseTypeMapping("uint8", "uint8")),
+	dialectTypeMapping("clickhouse", baseTypeMapping("uint16", "uint16")),
+	dialectTypeMapping("clickhouse", baseTypeMapping("uint32", "uint32")),
+	dialectTypeMapping("clickhouse", baseTypeMapping("uint64", "uint64")),
+	// 0.3 ClickHouse 超长整数 - Go 没有对应的原生类型，以字符串形式读写
+	dialectTypeMapping("clickhouse", baseTypeMapping("string", "int128", "int256", "uint128", "uint256")),
+	// 1. tinyint(1) - 布尔类型
 	{
-		matcher: func(dbType string) bool {
-			return strings.Contains(dbType, "tinyint(1)")
+		matcher: func(t columnType) bool {
+			return t.base == "tinyint" && t.length != nil && *t.length == 1
 		},
 		goType:         "bool",
 		nullableGoType: "*bool",
 	},
-	// 4. tinyint - 小整数
-	{
-		matcher: func(dbType string) bool {
-			return strings.Contains(dbType, "tinyint")
-		},
-		goType:         "int8",
-		nullableGoType: "*int8",
-	},
-	// 5. int unsigned - 无符号整数
-	{
-		matcher: func(dbType string) bool {
-			return strings.HasPrefix(dbType, "int") && strings.Contains(dbType, "unsigned")
-		},
-		goType:         "uint",
-		nullableGoType: "*uint",
-	},
-	// 6. int - 有符号整数
-	{
-		matcher: func(dbType string) bool {
-			return strings.HasPrefix(dbType, "int")
-		},
-		goType:         "int",
-		nullableGoType: "*int",
-	},
-	// 7. float - 单精度浮点数
-	{
-		matcher: func(dbType string) bool {
-			return strings.Contains(dbType, "float")
-		},
-		goType:         "float32",
-		nullableGoType: "*float32",
-	},
-	// 8. double/decimal - 双精度浮点数
-	{
-		matcher: func(dbType string) bool {
-			return strings.Contains(dbType, "double") || strings.Contains(dbType, "decimal")
-		},
-		goType:         "float64",
-		nullableGoType: "*float64",
-	},
-	// 9. datetime/timestamp - 日期时间类型
-	{
-		matcher: func(dbType string) bool {
-			return strings.Contains(dbType, "datetime") || strings.Contains(dbType, "timestamp")
-		},
-		goType:         "time.Time",
-		nullableGoType: "*time.Time",
-	},
-	// 10. date - 日期类型
-	{
-		matcher: func(dbType string) bool {
-			return strings.Contains(dbType, "date")
+	// 2. 整数 - 无符号类型使用相同宽度的 uint
+	unsignedTypeMapping("uint8", "tinyint"),
+	baseTypeMapping("int8", "tinyint"),
+	unsignedTypeMapping("uint16", "smallint"),
+	baseTypeMapping("int16", "smallint", "smallserial", "int2"),
+	unsignedTypeMapping("uint32", "mediumint"),
+	baseTypeMapping("int32", "mediumint"),
+	unsignedTypeMapping("uint32", "int", "integer"),
+	// SQLite 的 INTEGER 为 64 位（INTEGER PRIMARY KEY 即 rowid），INT 同样按 64 位整数存储
+	dialectTypeMapping("sqlite", baseTypeMapping("int64", "int", "integer")),
+	baseTypeMapping("int32", "int", "integer", "int4", "serial"),
+	unsignedTypeMapping("uint64", "bigint"),
+	baseTypeMapping("int64", "bigint", "bigserial", "int8"),
+	// 3. year - MySQL 年份
+	baseTypeMapping("int16", "year"),
+	// 4. 浮点数和定点数
+	baseTypeMapping("float32", "float", "float32", "real", "float4"),
+	baseTypeMapping("float64", "double", "double precision", "float64", "float8",
+		"decimal", "numeric", "dec", "fixed", "decimal32", "decimal64", "decimal128", "decimal256"),
+	// 5. boolean - PostgreSQL、ClickHouse 布尔类型
+	baseTypeMapping("bool", "bool", "boolean"),
+	// 6. 二进制类型（binary、blob、bit、空间类型）- 可空时使用 nil 切片表示 NULL
+	bytesTypeMapping("binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bytea", "bit", "bit varying", "varbit",
+		"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection", "geomcollection"),
+	// 7. 日期时间类型
+	baseTypeMapping("time.Time", "date", "date32", "datetime", "datetime64", "timestamp", "timestamptz",
+		"timestamp with time zone", "timestamp without time zone"),
+	// 8. 时间和时间间隔 - MySQL 的 time 可以超过 24 小时，以字符串形式读写
+	baseTypeMapping("string", "time", "timetz", "time with time zone", "time without time zone", "interval"),
+}
+
+// baseTypeMapping 创建按基础类型精确匹配的映射规则，可空时使用指针类型
+// 无符号类型只在没有对应的 unsignedTypeMapping 时才会匹配到这里
+func baseTypeMapping(goType string, baseTypes ...string) typeMapping {
+	return typeMapping{
+		matcher: func(t columnType) bool {
+			return lo.Contains(baseTypes, t.base)
 		},
-		goType:         "time.Time",
-		nullableGoType: "*time.Time",
-	},
-	// 11. varchar/char/text/blob - 字符串类型
-	{
-		matcher: func(dbType string) bool {
-			return strings.Contains(dbType, "varchar") ||
-				strings.Contains(dbType, "char") ||
-				strings.Contains(dbType, "text") ||
-				strings.Contains(dbType, "blob")
+		goType:         goType,
+		nullableGoType: "*" + goType,
+	}
+}
+
+// unsignedTypeMapping 创建按基础类型匹配无符号类型的映射规则，可空时使用指针类型
+func unsignedTypeMapping(goType string, baseTypes ...string) typeMapping {
+	return typeMapping{
+		matcher: func(t columnType) bool {
+			return t.unsigned && lo.Contains(baseTypes, t.base)
 		},
-		goType:         "string",
-		nullableGoType: "*string",
-	},
-	// 12. json - JSON 类型
-	{
-		matcher: func(dbType string) bool {
-			return strings.Contains(dbType, "json")
+		goType:         goType,
+		nullableGoType: "*" + goType,
+	}
+}
+
+// dialectTypeMapping 限定映射规则只在指定的数据库方言下使用
+func dialectTypeMapping(dialect string, mapping typeMapping) typeMapping {
+	mapping.dialects = append(mapping.dialects, dialect)
+	return mapping
+}
+
+// bytesTypeMapping 创建映射为 []byte 的规则，可空时使用 nil 切片表示 NULL
+func bytesTypeMapping(baseTypes ...string) typeMapping {
+	return typeMapping{
+		matcher: func(t columnType) bool {
+			return lo.Contains(baseTypes, t.base)
 		},
-		goType:         "string",
-		nullableGoType: "*string",
-	},
+		goType:         "[]byte",
+		nullableGoType: "[]byte",
+	}
+}
+
+// unwrapClickhouseType 去除 ClickHouse 的 LowCardinality(...) 和 Nullable(...) 包装，返回实际的数据类型
+// 是否可空由解析器写入 IsNullable，这里只关心内部类型
+// 示例:
+//   - lowcardinality(nullable(string)) -> string
+//   - nullable(datetime64(3)) -> datetime64(3)
+func unwrapClickhouseType(dbType string) string {
+	for _, wrapper := range []string{"lowcardinality(", "nullable("} {
+		if strings.HasPrefix(dbType, wrapper) && strings.HasSuffix(dbType, ")") {
+			dbType = dbType[len(wrapper) : len(dbType)-1]
+		}
+	}
+	return dbType
 }
 
 // GetGoType 根据列的数据库类型返回对应的 Go 类型
@@ -132,15 +216,43 @@ var typeMappings = []typeMapping{
 //
 // 示例:
 //   - bigint unsigned + 非空 -> uint64
+//   - int unsigned + 非空 -> uint32
 //   - varchar(128) + 可空 -> *string
+//   - varbinary(16) + 可空 -> []byte
 //   - datetime + 非空 -> time.Time
+//   - timestamp with time zone + 非空 -> time.Time
+//   - LowCardinality(String) + 非空 -> string
+//   - type_overrides 匹配的列直接返回覆盖后的类型（如 decimal.Decimal）
+//   - ENUM、SET 列返回生成的枚举类型（如 TOrderStatus），不带包名
+//
+// 不区分数据库方言，只使用各方言通用的规则，ClickHouse 的 Int8 等方言专有的类型见 dialectGoType
 func GetGoType(col model.Column) string {
-	// 将类型转换为小写便于比较
-	dbType := strings.ToLower(col.Type)
+	return dialectGoType("", col)
+}
+
+// dialectGoType 按数据库方言返回列对应的 Go 类型，dialect 为空时只使用各方言通用的规则
+// 示例:
+//   - clickhouse + Int8 -> int8
+//   - postgres + int8 -> int64
+func dialectGoType(dialect string, col model.Column) string {
+	if col.GoType != "" {
+		return col.GoType
+	}
+	if col.EnumTypeName != "" {
+		if col.IsNullable {
+			return "*" + col.EnumTypeName
+		}
+		return col.EnumTypeName
+	}
+
+	parsed := parseColumnType(col.Type)
 
 	// 遍历所有映射规则，找到第一个匹配的规则
 	for _, mapping := range typeMappings {
-		if mapping.matcher(dbType) {
+		if len(mapping.dialects) > 0 && !lo.Contains(mapping.dialects, dialect) {
+			continue
+		}
+		if mapping.matcher(parsed) {
 			// 根据是否可空返回对应的 Go 类型
 			if col.IsNullable {
 				return mapping.nullableGoType
@@ -202,3 +314,80 @@ func TrimPrefix(s, prefix string) string {
 func TrimPointer(s string) string {
 	return tool.TrimPrefix(s, "*")
 }
+
+// IsPointer 判断类型是否为指针类型
+// 用于模板中判断是否需要生成 WithXxxValue 这类取地址的便捷方法
+// 示例:
+//   - IsPointer("*string") -> true
+//   - IsPointer("[]byte") -> false
+func IsPointer(goType string) bool {
+	return strings.HasPrefix(goType, "*")
+}
+
+// IsNillable 判断类型的零值是否为 nil，可以直接与 nil 比较
+// type_overrides 指定的结构体类型（如 decimal.Decimal）不能与 nil 比较，生成的查询条件改用 reflect 判断零值
+// 示例:
+//   - IsNillable("*string") -> true
+//   - IsNillable("[]byte") -> true
+//   - IsNillable("decimal.Decimal") -> false
+func IsNillable(goType string) bool {
+	return strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[")
+}
+
+// QuoteColumn 按方言为 SQL 条件中的列名加引号，返回值可直接嵌入 Go 的双引号字符串字面量
+// PostgreSQL 会把未加引号的标识符折叠为小写，因此含大写字母的列名需要用双引号包裹
+// MySQL 的列名大小写不敏感，保持原样
+// 示例:
+//   - QuoteColumn("postgres", "sessionId") -> \"sessionId\"
+//   - QuoteColumn("postgres", "session_id") -> session_id
+//   - QuoteColumn("mysql", "sessionId") -> sessionId
+func QuoteColumn(dialect, columnName string) string {
+	if dialect == "postgres" && columnName != strings.ToLower(columnName) {
+		return `\"` + columnName + `\"`
+	}
+	return columnName
+}
+
+// TimeRangeColumn 选出用于时间范围查询的列，没有时间类型的列时返回 nil
+// 优先选择出现在分区键中的列，其次是排序键中的列，最后是第一个时间列，
+// 这样生成的时间范围查询能尽量命中 ClickHouse 的分区裁剪和稀疏索引
+// 示例:
+//   - PARTITION BY toYYYYMM(event_time) ORDER BY (user_id, event_time) -> event_time
+func TimeRangeColumn(schema model.Schema) *model.Column {
+	timeColumns := lo.Filter(schema.Columns, func(col model.Column, _ int) bool {
+		return TrimPointer(GetGoType(col)) == "time.Time"
+	})
+	if len(timeColumns) == 0 {
+		return nil
+	}
+
+	for _, keyExpression := range []string{schema.PartitionKey, schema.SortingKey} {
+		for _, col := range timeColumns {
+			if containsIdentifier(keyExpression, col.ColumnName) {
+				return &col
+			}
+		}
+	}
+	return &timeColumns[0]
+}
+
+// containsIdentifier 判断表达式中是否以完整标识符的形式引用了指定列
+// 示例:
+//   - containsIdentifier("toYYYYMM(event_time)", "event_time") -> true
+//   - containsIdentifier("toYYYYMM(event_time)", "time") -> false
+func containsIdentifier(expression, identifier string) bool {
+	isIdentChar := func(ch byte) bool {
+		return ch == '_' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+	}
+	for offset := 0; ; {
+		idx := strings.Index(expression[offset:], identifier)
+		if idx < 0 {
+			return false
+		}
+		start, end := offset+idx, offset+idx+len(identifier)
+		if (start == 0 || !isIdentChar(expression[start-1])) && (end == len(expression) || !isIdentChar(expression[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
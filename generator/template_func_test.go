@@ -0,0 +1,183 @@
+package generator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/model"
+)
+
+func TestGetGoType(t *testing.T) {
+	for _, testCase := range []struct {
+		dialect  string
+		dbType   string
+		nullable bool
+		expected string
+	}{
+		// MySQL 整数
+		{"mysql", "tinyint(1)", false, "bool"},
+		{"mysql", "tinyint(4)", false, "int8"},
+		{"mysql", "tinyint unsigned", true, "*uint8"},
+		{"mysql", "smallint(6)", false, "int16"},
+		{"mysql", "smallint unsigned", false, "uint16"},
+		{"mysql", "mediumint", false, "int32"},
+		{"mysql", "mediumint(8) unsigned", false, "uint32"},
+		{"mysql", "int(11)", false, "int32"},
+		{"mysql", "int unsigned", false, "uint32"},
+		{"mysql", "int(10) unsigned zerofill", true, "*uint32"},
+		{"mysql", "int(11) UNSIGNED", false, "uint32"},
+		{"mysql", "bigint(20)", false, "int64"},
+		{"mysql", "bigint unsigned", false, "uint64"},
+		{"mysql", "year(4)", false, "int16"},
+		// MySQL 浮点数、定点数
+		{"mysql", "float", false, "float32"},
+		{"mysql", "double", true, "*float64"},
+		{"mysql", "decimal(10,2)", false, "float64"},
+		{"mysql", "decimal(10,2) unsigned", false, "float64"},
+		// MySQL 日期时间
+		{"mysql", "date", false, "time.Time"},
+		{"mysql", "datetime(3)", true, "*time.Time"},
+		{"mysql", "timestamp", false, "time.Time"},
+		{"mysql", "time", false, "string"},
+		{"mysql", "time(3)", true, "*string"},
+		// MySQL 二进制和空间类型
+		{"mysql", "bit(1)", false, "[]byte"},
+		{"mysql", "binary(16)", false, "[]byte"},
+		{"mysql", "varbinary(255)", true, "[]byte"},
+		{"mysql", "varbinary(64) BINARY", true, "[]byte"},
+		{"mysql", "blob", false, "[]byte"},
+		{"mysql", "longblob", true, "[]byte"},
+		{"mysql", "geometry", false, "[]byte"},
+		{"mysql", "point", false, "[]byte"},
+		// MySQL 字符串
+		{"mysql", "varchar(64)", false, "string"},
+		{"mysql", "longtext", true, "*string"},
+		{"mysql", "varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin", false, "string"},
+		{"mysql", "char(1) binary", false, "string"},
+		{"mysql", "json", false, "string"},
+		{"mysql", "enum('draft','published')", false, "string"},
+		{"mysql", "set('a','b')", false, "string"},
+		// 自定义类型名中包含其他类型名时不应误判
+		{"mysql", "update_status", false, "string"},
+		{"mysql", "pointer_type", false, "string"},
+		// PostgreSQL
+		{"postgres", "integer", false, "int32"},
+		{"postgres", "serial", false, "int32"},
+		{"postgres", "character varying(128)", false, "string"},
+		{"postgres", "numeric(10,2)", false, "float64"},
+		{"postgres", "double precision", false, "float64"},
+		{"postgres", "real", false, "float32"},
+		{"postgres", "boolean", true, "*bool"},
+		{"postgres", "bytea", true, "[]byte"},
+		{"postgres", "timestamp(3) with time zone", false, "time.Time"},
+		{"postgres", "time without time zone", false, "string"},
+		{"postgres", "interval", false, "string"},
+		{"postgres", "integer[]", false, "string"},
+		{"postgres", "bigserial", false, "int64"},
+		// ClickHouse
+		{"clickhouse", "UInt32", false, "uint32"},
+		{"clickhouse", "Int128", false, "string"},
+		{"clickhouse", "Float64", false, "float64"},
+		{"clickhouse", "Decimal(18, 4)", false, "float64"},
+		{"clickhouse", "LowCardinality(Nullable(String))", true, "*string"},
+		{"clickhouse", "Nullable(DateTime64(3, 'Asia/Shanghai'))", true, "*time.Time"},
+		{"clickhouse", "Array(DateTime)", false, "string"},
+		{"clickhouse", "Enum8('update' = 1, 'delete' = 2)", false, "string"},
+		{"clickhouse", "Bool", false, "bool"},
+		// SQLite
+		{"sqlite", "INTEGER", false, "int64"},
+		{"sqlite", "int", true, "*int64"},
+		{"sqlite", "BLOB", true, "[]byte"},
+		{"sqlite", "decimal(10, 2)", false, "float64"},
+	} {
+		goType := dialectGoType(testCase.dialect, model.Column{ColumnName: "c", Type: testCase.dbType, IsNullable: testCase.nullable})
+		if goType != testCase.expected {
+			t.Errorf("类型映射不正确 [%s %s, nullable=%v]: %s, 期望: %s", testCase.dialect, testCase.dbType, testCase.nullable, goType, testCase.expected)
+		}
+	}
+}
+
+// TestDialectGoTypeFixedWidthIntegers 同名的定长整数类型在不同方言中宽度不同（ClickHouse 的 Int8 为 8 位，PostgreSQL 的 int8 为 64 位）
+func TestDialectGoTypeFixedWidthIntegers(t *testing.T) {
+	for dialect, expectations := range map[string]map[string]string{
+		"mysql":      {"int8": "int64", "int2": "int16", "int4": "int32"},
+		"postgres":   {"int8": "int64", "int2": "int16", "int4": "int32"},
+		"sqlite":     {"int8": "int64", "int2": "int16", "int4": "int32", "integer": "int64"},
+		"clickhouse": {"Int8": "int8", "Int16": "int16", "Int32": "int32", "Int64": "int64", "UInt8": "uint8"},
+		"":           {"int8": "int64", "Int8": "int64", "UInt8": "string"},
+	} {
+		for dbType, expected := range expectations {
+			if goType := dialectGoType(dialect, model.Column{ColumnName: "c", Type: dbType}); goType != expected {
+				t.Errorf("类型映射不正确 [%s %s]: %s, 期望: %s", dialect, dbType, goType, expected)
+			}
+		}
+	}
+}
+
+func TestParseColumnType(t *testing.T) {
+	parsed := parseColumnType("decimal(10, 2) unsigned zerofill")
+	if parsed.base != "decimal" || *parsed.length != 10 || *parsed.scale != 2 || !parsed.unsigned {
+		t.Errorf("decimal 类型解析不正确: %+v", parsed)
+	}
+	parsed = parseColumnType("timestamp(6) without time zone")
+	if parsed.base != "timestamp without time zone" || *parsed.length != 6 || parsed.scale != nil || parsed.unsigned {
+		t.Errorf("timestamp 类型解析不正确: %+v", parsed)
+	}
+	parsed = parseColumnType("text[][]")
+	if parsed.base != "text" || !parsed.array || parsed.length != nil {
+		t.Errorf("数组类型解析不正确: %+v", parsed)
+	}
+}
+
+func TestEnumConstants(t *testing.T) {
+	constants := EnumConstants(model.Column{
+		EnumTypeName: "TOrderStatus",
+		EnumValues:   []string{"draft", "in-progress", "in_progress", "", "已完成"},
+	})
+	var names []string
+	for _, constant := range constants {
+		names = append(names, constant.Name)
+	}
+	expected := "[TOrderStatusDraft TOrderStatusInProgress TOrderStatusInProgress3 TOrderStatusEmpty TOrderStatusValue5]"
+	if fmt.Sprint(names) != expected {
+		t.Errorf("枚举常量名不正确: %v, 期望: %s", names, expected)
+	}
+	if constants[1].Value != "in-progress" {
+		t.Errorf("枚举常量值不正确: %+v", constants[1])
+	}
+
+	goType := GetGoType(model.Column{Type: "enum('a','b')", IsNullable: true, EnumTypeName: "TOrderStatus"})
+	if goType != "*TOrderStatus" {
+		t.Errorf("可空枚举列的类型不正确: %s", goType)
+	}
+}
+
+func TestNullableStyleGoType(t *testing.T) {
+	for _, testCase := range []struct {
+		style    string
+		goType   string
+		expected string
+		base     string
+		value    string
+	}{
+		{"pointer", "*string", "*string", "string", "&v"},
+		{"sql_null", "*string", "sql.NullString", "string", "sql.NullString{String: v, Valid: true}"},
+		{"sql_null", "*time.Time", "sql.NullTime", "time.Time", "sql.NullTime{Time: v, Valid: true}"},
+		{"sql_null", "*uint8", "sql.NullByte", "uint8", "sql.NullByte{Byte: v, Valid: true}"},
+		{"sql_null", "*uint32", "sql.Null[uint32]", "uint32", "sql.Null[uint32]{V: v, Valid: true}"},
+		{"generic", "*int64", "sql.Null[int64]", "int64", "sql.Null[int64]{V: v, Valid: true}"},
+		{"generic", "*po.TOrderStatus", "sql.Null[po.TOrderStatus]", "po.TOrderStatus", "sql.Null[po.TOrderStatus]{V: v, Valid: true}"},
+		{"generic", "[]byte", "[]byte", "[]byte", ""},
+	} {
+		goType := nullableStyleGoType(testCase.style, testCase.goType)
+		if goType != testCase.expected {
+			t.Errorf("可空类型不正确 [%s, %s]: %s, 期望: %s", testCase.style, testCase.goType, goType, testCase.expected)
+		}
+		if base := NullBaseType(goType); base != testCase.base {
+			t.Errorf("可空类型的值类型不正确 [%s]: %s, 期望: %s", goType, base, testCase.base)
+		}
+		if testCase.value != "" && NullValue(goType, "v") != testCase.value {
+			t.Errorf("可空类型的赋值表达式不正确 [%s]: %s, 期望: %s", goType, NullValue(goType, "v"), testCase.value)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/samber/lo"
+)
+
+// 内置的产物类型，输出包默认使用 package_name 中对应的配置
+const (
+	ArtifactPo   = "po"
+	ArtifactDto  = "dto"
+	ArtifactVo   = "vo"
+	ArtifactDao  = "dao"
+	ArtifactTool = "tool"
+)
+
+// artifactKinds 内置的产物类型，其他类型的产物需要在模板集中声明输出包
+var artifactKinds = []string{ArtifactPo, ArtifactDto, ArtifactVo, ArtifactDao, ArtifactTool}
+
+// 产物的生成方式
+const (
+	ScopeTable     = "table"     // 每张表生成一个文件，文件名模式中的 %s 替换为实体的文件名
+	ScopeAll       = "all"       // 所有表生成到一个文件，FileName 为文件名
+	ScopeTemplates = "templates" // Template 为模板目录，目录中每个 .template 生成一个同名的 .go 文件，如工具代码
+)
+
+// DefaultFramework use_framework 为空时使用的模板集
+const DefaultFramework = "gorm"
+
+// Artifact 模板集生成的一类代码文件
+type Artifact struct {
+	Kind             string            // 产物类型，内置 po、dto、vo、dao、tool，也可以是自定义的类型（如 service）
+	Template         string            // 模板在 TemplateSet.FS 中的路径，Scope 为 templates 时为模板目录
+	DialectTemplates map[string]string // 按数据库方言替换的模板路径，如 clickhouse 的 DAO 使用分析型模板
+	Package          string            // 输出包路径，为空时使用 package_name 中对应类型的配置，自定义类型必须声明
+	Scope            string            // 生成方式: table、all、templates，为空时 tool 为 templates，其他为 table
+	FileName         string            // 文件名，Scope 为 table 时为文件名模式（如 %s_dao.go -> user_dao.go），templates 不使用
+	Static           bool              // 模板不使用表结构（如工具代码），只生成此类产物时不解析表结构
+}
+
+// scope 返回产物的生成方式，未声明时 tool 按模板目录生成，其他产物每张表生成一个文件
+func (a Artifact) scope() string {
+	if a.Scope != "" {
+		return a.Scope
+	}
+	if a.Kind == ArtifactTool {
+		return ScopeTemplates
+	}
+	return ScopeTable
+}
+
+// TemplateSet 一套框架模板，声明生成哪些产物、每个产物的模板、输出包和文件名，以及额外的模板函数
+// 内置 gorm 和 itea-go 两套模板，其他框架通过 RegisterTemplateSet 注册后在 use_framework 中引用
+type TemplateSet struct {
+	Name      string           // 名称，对应 use_framework
+	FS        fs.FS            // 模板所在的文件系统（如 embed.FS、os.DirFS），为空时使用内置模板
+	Artifacts []Artifact       // 生成的产物，按声明的顺序生成，每种类型最多一个，未声明的产物不生成
+	Funcs     template.FuncMap // 额外的模板函数，与内置函数同名时覆盖内置函数
+}
+
+var (
+	templateSetsMu sync.RWMutex
+	templateSets   = map[string]TemplateSet{
+		DefaultFramework: builtinTemplateSet(DefaultFramework, templatePathPrefix),
+		"itea-go":        builtinTemplateSet("itea-go", templatePathPrefix+"itea-go/"),
+	}
+)
+
+// builtinTemplateSet 返回内置模板集，dir 为 po/dto/vo/dao 模板所在的目录，工具模板和 ClickHouse 的 DAO 模板各框架共用
+func builtinTemplateSet(name, dir string) TemplateSet {
+	return TemplateSet{
+		Name: name,
+		FS:   templateFS,
+		Artifacts: []Artifact{
+			{Kind: ArtifactPo, Template: dir + "po.template", Scope: ScopeTable, FileName: "%s.go"},
+			{Kind: ArtifactDto, Template: dir + "dto.template", Scope: ScopeTable, FileName: "%s_dto.go"},
+			{Kind: ArtifactVo, Template: dir + "vo.template", Scope: ScopeTable, FileName: "%s_vo.go"},
+			{
+				Kind:     ArtifactDao,
+				Template: dir + "dao.template",
+				// ClickHouse 表是追加写入模型，DAO 使用专门的分析型模板（不区分框架）
+				DialectTemplates: map[string]string{"clickhouse": templatePathPrefix + "clickhouse/dao.template"},
+				Scope:            ScopeTable,
+				FileName:         "%s_dao.go",
+			},
+			{Kind: ArtifactTool, Template: templatePathPrefix + "tools", Scope: ScopeTemplates, Static: true},
+		},
+	}
+}
+
+// RegisterTemplateSet 注册模板集，注册后可以通过 use_framework 使用，与已注册的模板集（包括内置的）同名时覆盖
+// 需要在创建生成器之前注册
+//
+// 使用示例:
+//
+//	//go:embed templates
+//	var templates embed.FS
+//
+//	generator.RegisterTemplateSet(generator.TemplateSet{
+//	    Name: "kratos",
+//	    FS:   templates,
+//	    Artifacts: []generator.Artifact{
+//	        {Kind: generator.ArtifactPo, Template: "templates/po.template", FileName: "%s.go"},
+//	        {Kind: generator.ArtifactDao, Template: "templates/repo.template", Package: "internal/data", FileName: "%s_repo.go"},
+//	        {Kind: "wire", Template: "templates/wire.template", Package: "internal/data", Scope: generator.ScopeAll, FileName: "wire_set.go"},
+//	    },
+//	})
+func RegisterTemplateSet(set TemplateSet) error {
+	if err := set.validate(); err != nil {
+		return err
+	}
+	if set.FS == nil {
+		set.FS = templateFS
+	}
+
+	templateSetsMu.Lock()
+	defer templateSetsMu.Unlock()
+	if _, ok := templateSets[set.Name]; ok {
+		log.Printf("🔁 模板集 %s 已注册，使用新注册的模板集覆盖", set.Name)
+	}
+	templateSets[set.Name] = set
+	return nil
+}
+
+// LookupTemplateSet 按名称查找已注册的模板集，名称为空时返回默认的 gorm 模板集
+func LookupTemplateSet(name string) (TemplateSet, bool) {
+	if name == "" {
+		name = DefaultFramework
+	}
+	templateSetsMu.RLock()
+	defer templateSetsMu.RUnlock()
+	set, ok := templateSets[name]
+	return set, ok
+}
+
+// TemplateSetNames 返回所有已注册的模板集名称，按名称排序
+func TemplateSetNames() []string {
+	templateSetsMu.RLock()
+	defer templateSetsMu.RUnlock()
+	names := lo.Keys(templateSets)
+	sort.Strings(names)
+	return names
+}
+
+// validate 校验模板集的名称和产物声明
+func (s TemplateSet) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("模板集名称不能为空")
+	}
+	if len(s.Artifacts) == 0 {
+		return fmt.Errorf("模板集 %s 没有声明任何产物", s.Name)
+	}
+	kinds := make(map[string]bool)
+	for _, artifact := range s.Artifacts {
+		if artifact.Kind == "" {
+			return fmt.Errorf("模板集 %s 中存在未声明类型的产物", s.Name)
+		}
+		if kinds[artifact.Kind] {
+			return fmt.Errorf("模板集 %s 中的产物 %s 重复声明", s.Name, artifact.Kind)
+		}
+		kinds[artifact.Kind] = true
+		if artifact.Template == "" {
+			return fmt.Errorf("模板集 %s 中的产物 %s 缺少模板路径", s.Name, artifact.Kind)
+		}
+		if !lo.Contains(artifactKinds, artifact.Kind) && artifact.Package == "" {
+			return fmt.Errorf("模板集 %s 中的自定义产物 %s 必须声明输出包 Package（内置产物类型: %s）", s.Name, artifact.Kind, strings.Join(artifactKinds, "、"))
+		}
+		switch artifact.scope() {
+		case ScopeTable:
+			if strings.Count(artifact.FileName, "%s") != 1 {
+				return fmt.Errorf("模板集 %s 中的产物 %s 的文件名模式必须包含一个 %%s: %q", s.Name, artifact.Kind, artifact.FileName)
+			}
+		case ScopeAll:
+			if artifact.FileName == "" || strings.Contains(artifact.FileName, "%") {
+				return fmt.Errorf("模板集 %s 中的产物 %s 的文件名不能为空，也不能包含 %%: %q", s.Name, artifact.Kind, artifact.FileName)
+			}
+		case ScopeTemplates:
+		default:
+			return fmt.Errorf("模板集 %s 中的产物 %s 的生成方式不支持: %s，请使用 %s、%s 或 %s", s.Name, artifact.Kind, artifact.Scope, ScopeTable, ScopeAll, ScopeTemplates)
+		}
+	}
+	return nil
+}
+
+// CheckFramework 检查 use_framework 指定的模板集是否已注册
+// 未注册时生成器使用默认的 gorm 模板集，由调用方决定是否中止生成
+func (g *Generator) CheckFramework() error {
+	if _, ok := LookupTemplateSet(g.configger.GenerateOption.UseFramework); !ok {
+		return fmt.Errorf("未注册的框架: %s，可用的框架: %s", g.configger.GenerateOption.UseFramework, strings.Join(TemplateSetNames(), "、"))
+	}
+	return nil
+}
+
+// CheckArtifacts 检查 artifacts 中选择的产物是否在模板集中声明，选择的单个模板（如 tool/ptr）是否存在
+func (g *Generator) CheckArtifacts() error {
+	kinds := lo.Map(g.templateSet.Artifacts, func(artifact Artifact, _ int) string {
+		return artifact.Kind
+	})
+	for _, selection := range g.configger.GenerateOption.Artifacts {
+		kind, templateName, hasTemplate := strings.Cut(selection, "/")
+		artifact, ok := g.artifact(kind)
+		if !ok {
+			return fmt.Errorf("artifacts 中的产物 %s 没有在模板集 %s 中声明，可选的产物: %s", kind, g.templateSet.Name, strings.Join(kinds, "、"))
+		}
+		if !hasTemplate {
+			continue
+		}
+		if artifact.scope() != ScopeTemplates {
+			return fmt.Errorf("artifacts 中的 %s 不合法: 产物 %s 不是按模板目录生成的，不能选择单个模板", selection, kind)
+		}
+		templateFileNames, err := g.templateNames(artifact)
+		if err != nil {
+			return err
+		}
+		templateNames := lo.Map(templateFileNames, func(templateFileName string, _ int) string {
+			return strings.TrimSuffix(templateFileName, ".template")
+		})
+		if !lo.Contains(templateNames, strings.TrimSuffix(templateName, ".template")) {
+			return fmt.Errorf("artifacts 中的 %s 不合法: 产物 %s 中没有模板 %s，可选的模板: %s", selection, kind, templateName, strings.Join(templateNames, "、"))
+		}
+	}
+	return nil
+}
+
+// artifactSelected 判断产物是否被 artifacts 选中，未配置 artifacts 时选中所有产物
+// 选择了产物中的单个模板（如 tool/ptr）时该产物同样被选中，由 templateSelected 决定生成哪些模板
+func (g *Generator) artifactSelected(kind string) bool {
+	selections := g.configger.GenerateOption.Artifacts
+	return len(selections) == 0 || lo.SomeBy(selections, func(selection string) bool {
+		return selection == kind || strings.HasPrefix(selection, kind+"/")
+	})
+}
+
+// artifactFullySelected 判断产物的所有文件是否都会生成，artifacts 中只选择了产物中的单个模板（如 tool/ptr）时返回 false
+func (g *Generator) artifactFullySelected(kind string) bool {
+	selections := g.configger.GenerateOption.Artifacts
+	return len(selections) == 0 || lo.Contains(selections, kind)
+}
+
+// templateSelected 判断按模板目录生成的产物中的模板是否被选中，artifacts 中直接选择产物类型时选中其中所有的模板
+func (g *Generator) templateSelected(kind, templateFileName string) bool {
+	selections := g.configger.GenerateOption.Artifacts
+	templateName := strings.TrimSuffix(templateFileName, ".template")
+	return len(selections) == 0 || lo.SomeBy(selections, func(selection string) bool {
+		return selection == kind || strings.TrimSuffix(selection, ".template") == kind+"/"+templateName
+	})
+}
+
+// NeedsSchemas 判断选中的产物中是否有依赖表结构的产物，没有时不需要连接数据库、解析表结构
+func (g *Generator) NeedsSchemas() bool {
+	return lo.SomeBy(g.Artifacts(), func(artifact Artifact) bool {
+		return !artifact.Static
+	})
+}
+
+// artifact 返回当前模板集中指定类型的产物，模板集没有声明该产物时返回 false
+func (g *Generator) artifact(kind string) (Artifact, bool) {
+	return lo.Find(g.templateSet.Artifacts, func(artifact Artifact) bool {
+		return artifact.Kind == kind
+	})
+}
+
+// artifactTemplate 返回产物在当前数据库方言下使用的模板路径
+func (g *Generator) artifactTemplate(artifact Artifact) string {
+	if templatePath, ok := artifact.DialectTemplates[g.dialect()]; ok {
+		return templatePath
+	}
+	return artifact.Template
+}
+
+// packagePath 返回产物的输出包路径，模板集中声明了 Package 时优先，否则使用 package_name 中的配置
+func (g *Generator) packagePath(kind string) string {
+	if artifact, ok := g.artifact(kind); ok && artifact.Package != "" {
+		return artifact.Package
+	}
+	packages := g.configger.GenerateOption.Package
+	switch kind {
+	case ArtifactPo:
+		return packages.PoPackage
+	case ArtifactDto:
+		return packages.DtoPackage
+	case ArtifactVo:
+		return packages.VoPackage
+	case ArtifactDao:
+		return packages.DaoPackage
+	case ArtifactTool:
+		return packages.ToolPackage
+	default:
+		return ""
+	}
+}
+
+// Artifacts 返回当前模板集中需要生成的产物，按模板集中声明的顺序排列，配置了 artifacts 时只返回选中的产物
+// 开启 all_model_in_one_file 时，PO 改为把所有表生成到 all_model_in_one_file_name 指定的文件中
+func (g *Generator) Artifacts() []Artifact {
+	option := g.configger.GenerateOption
+	return lo.FilterMap(g.templateSet.Artifacts, func(artifact Artifact, _ int) (Artifact, bool) {
+		if artifact.Kind == ArtifactPo && option.ModelAllInOneFile {
+			artifact.Scope = ScopeAll
+			artifact.FileName = option.ModelAllInOneFileName
+		}
+		return artifact, g.artifactSelected(artifact.Kind)
+	})
+}
@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"text/template"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/samber/lo"
+)
+
+// registerTestTemplateSet 注册测试用的模板集，测试结束后从注册表中删除
+func registerTestTemplateSet(t *testing.T, set TemplateSet) {
+	t.Helper()
+	if err := RegisterTemplateSet(set); err != nil {
+		t.Fatalf("注册模板集失败: %v", err)
+	}
+	t.Cleanup(func() {
+		templateSetsMu.Lock()
+		defer templateSetsMu.Unlock()
+		delete(templateSets, set.Name)
+	})
+}
+
+// TestRegisterTemplateSet 注册的模板集只生成声明的产物，并使用声明的输出包、文件名和模板函数
+func TestRegisterTemplateSet(t *testing.T) {
+	registerTestTemplateSet(t, TemplateSet{
+		Name: "test-repo",
+		FS: fstest.MapFS{
+			"po.template":   {Data: []byte("package {{ .PoPackageName }}\n{{ range .Schemas }}\n// {{ .Name | EntityName }} {{ Banner }}\ntype {{ .Name | EntityName }} struct{}\n{{ end }}")},
+			"repo.template": {Data: []byte("package {{ .DaoPackageName }}\n{{ range .Schemas }}\n// {{ .Name | EntityName }}Repo {{ Banner }}\ntype {{ .Name | EntityName }}Repo struct{}\n{{ end }}")},
+			"set.template":  {Data: []byte("package {{ .PackageName }}\n\n// Tables {{ len .Schemas }} 张表，VO 包 {{ .VoPackageName }}\nvar Tables = []string{ {{- range .Schemas }}\"{{ .Name }}\", {{ end -}} }\n")},
+		},
+		Artifacts: []Artifact{
+			{Kind: ArtifactPo, Template: "po.template", FileName: "%s.go"},
+			{Kind: ArtifactDao, Template: "repo.template", Package: "internal/data", FileName: "%s_repo.go"},
+			{Kind: "provider", Template: "set.template", Package: "internal/provider", Scope: ScopeAll, FileName: "provider_set.go"},
+		},
+		Funcs: template.FuncMap{"Banner": func() string { return "由模板集生成" }},
+	})
+	g, schemas := newTestGenerator(t, `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_name varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);`, config.NewBuilder().OutputPath(t.TempDir()).UseFramework("test-repo"))
+
+	files := renderArtifacts(t, g, schemas)
+	assertContains(t, files, map[string][]string{
+		"po/t_user.go":                 {"// TUser 由模板集生成"},
+		"internal/data/t_user_repo.go": {"package data\n\n// TUserRepo 由模板集生成"},
+		// 自定义产物类型使用相同的生成流程，所有表生成到一个文件
+		"internal/provider/provider_set.go": {"package provider\n\n// Tables 1 张表，VO 包 vo\nvar Tables = []string{\"t_user\"}"},
+	})
+	if len(files) != 3 {
+		t.Errorf("模板集没有声明的产物不应生成: %v", files)
+	}
+}
+
+// TestCheckFramework use_framework 指定的模板集没有注册时应返回错误
+func TestCheckFramework(t *testing.T) {
+	g := NewGenerator(config.NewBuilder().StatementMode("schema.sql").AllTables().OutputPath(t.TempDir()).UseFramework("missing").MustBuild())
+	if err := g.CheckFramework(); err == nil || !strings.Contains(err.Error(), "未注册的框架") {
+		t.Errorf("使用未注册的框架时应返回错误: %v", err)
+	}
+}
+
+// TestTemplateSetValidate 自定义产物必须声明输出包，所有表生成到一个文件的产物必须声明文件名
+func TestTemplateSetValidate(t *testing.T) {
+	if err := RegisterTemplateSet(TemplateSet{Name: "test-invalid", Artifacts: []Artifact{{Kind: "service", Template: "service.template", FileName: "%s_service.go"}}}); err == nil || !strings.Contains(err.Error(), "Package") {
+		t.Errorf("自定义产物类型没有声明输出包时应返回错误: %v", err)
+	}
+	if err := RegisterTemplateSet(TemplateSet{Name: "test-invalid", Artifacts: []Artifact{{Kind: ArtifactPo, Template: "po.template", Scope: ScopeAll}}}); err == nil {
+		t.Errorf("所有表生成到一个文件的产物没有声明文件名时应返回错误")
+	}
+}
+
+// TestSelectArtifacts artifacts 只生成选中的产物和单个工具模板，选择未声明的产物或不存在的模板时返回错误
+func TestSelectArtifacts(t *testing.T) {
+	const sql = `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_name varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);`
+	g, schemas := newTestGenerator(t, sql, config.NewBuilder().OutputPath(t.TempDir()).Artifacts("po", "dao", "tool/ptr"))
+	if err := g.CheckArtifacts(); err != nil {
+		t.Fatalf("检查 artifacts 失败: %v", err)
+	}
+	files := renderArtifacts(t, g, schemas)
+	for _, file := range []string{"po/t_user.go", "dao/t_user_dao.go", "tool/ptr.go"} {
+		if _, ok := files[file]; !ok {
+			t.Errorf("选中的产物没有生成: %s", file)
+		}
+	}
+	if len(files) != 3 {
+		t.Errorf("没有选中的产物不应生成: %v", lo.Keys(files))
+	}
+	if !g.NeedsSchemas() {
+		t.Errorf("选中了依赖表结构的产物时需要解析表结构")
+	}
+
+	g = NewGenerator(config.NewBuilder().StatementMode("schema.sql").AllTables().OutputPath(t.TempDir()).Artifacts("tool").MustBuild())
+	if g.NeedsSchemas() {
+		t.Errorf("只选中工具代码时不需要解析表结构")
+	}
+
+	for selection, expected := range map[string]string{
+		"service":      "没有在模板集 gorm 中声明",
+		"tool/missing": "可选的模板",
+		"dao/t_user":   "不能选择单个模板",
+	} {
+		g = NewGenerator(config.NewBuilder().StatementMode("schema.sql").AllTables().OutputPath(t.TempDir()).Artifacts(selection).MustBuild())
+		if err := g.CheckArtifacts(); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("artifacts 选择 %s 时应返回错误（%s）: %v", selection, expected, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
+)
+
+// majorVersionRegexp 匹配导入路径末尾的主版本号（如 /v2）
+var majorVersionRegexp = regexp.MustCompile(`^v[0-9]+$`)
+
+// parseGoType 将带导入路径的 Go 类型拆分为代码中使用的类型和导入路径，按最后一个 . 拆分导入路径和类型名
+// 示例:
+//   - github.com/shopspring/decimal.Decimal -> decimal.Decimal, github.com/shopspring/decimal
+//   - *gorm.io/datatypes.JSON -> *datatypes.JSON, gorm.io/datatypes
+//   - encoding/json.RawMessage -> json.RawMessage, encoding/json
+//   - gopkg.in/yaml.v3.Node -> yaml.Node, gopkg.in/yaml.v3
+//   - github.com/jackc/pgx/v5/pgtype.Numeric -> pgtype.Numeric, github.com/jackc/pgx/v5/pgtype
+//   - datatypes.JSON -> datatypes.JSON, ""（没有导入路径时不生成导入）
+//
+// 带导入路径时类型名和推断的包名必须是合法的标识符，否则返回错误
+func parseGoType(spec string) (goType string, importPath string, err error) {
+	modifiers := spec[:len(spec)-len(strings.TrimLeft(spec, "*[]"))]
+	qualified := spec[len(modifiers):]
+	slashIdx := strings.LastIndex(qualified, "/")
+	if slashIdx < 0 {
+		return spec, "", nil
+	}
+	dotIdx := strings.LastIndex(qualified, ".")
+	if dotIdx < slashIdx {
+		return "", "", fmt.Errorf("Go 类型 %s 缺少类型名，应为 导入路径.类型名 的形式（如 github.com/shopspring/decimal.Decimal）", spec)
+	}
+	importPath, typeName := qualified[:dotIdx], qualified[dotIdx+1:]
+	if !token.IsIdentifier(typeName) {
+		return "", "", fmt.Errorf("Go 类型 %s 的类型名 %q 不是合法的标识符", spec, typeName)
+	}
+	packageName := assumedPackageName(importPath)
+	if !token.IsIdentifier(packageName) {
+		return "", "", fmt.Errorf("无法从导入路径 %s 推断包名", importPath)
+	}
+	return modifiers + packageName + "." + typeName, importPath, nil
+}
+
+// importPackageName 根据导入路径推断包名，取最后一段路径，跳过主版本号后缀
+// 示例:
+//   - github.com/shopspring/decimal -> decimal
+//   - github.com/jackc/pgx/v5/pgtype -> pgtype
+//   - github.com/jackc/pgx/v5 -> pgx
+func importPackageName(importPath string) string {
+	elements := strings.Split(importPath, "/")
+	name := elements[len(elements)-1]
+	if majorVersionRegexp.MatchString(name) && len(elements) > 1 {
+		name = elements[len(elements)-2]
+	}
+	return name
+}
+
+// assumedPackageName 按 goimports 的惯例推断包名：在 importPackageName 的基础上去掉 go- 前缀，截断到第一个不能出现在标识符中的字符
+// 示例:
+//   - gopkg.in/yaml.v3 -> yaml
+//   - github.com/mattn/go-sqlite3 -> sqlite3
+func assumedPackageName(importPath string) string {
+	name := strings.TrimPrefix(importPackageName(importPath), "go-")
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// nullableGoType 返回可空列默认使用的 Go 类型，指针、切片、map 本身可以表示 NULL，保持不变
+func nullableGoType(goType string) string {
+	if strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") {
+		return goType
+	}
+	return "*" + goType
+}
+
+// matchTypeOverride 判断类型覆盖规则是否匹配指定表的列
+// column 规则匹配 表名.列名，db_type 规则同时匹配完整的类型和解析后的基础类型（去掉长度、unsigned 等修饰）
+func matchTypeOverride(override config.TypeOverride, tableName string, column model.Column) bool {
+	if override.Column != "" {
+		matched, _ := path.Match(strings.ToLower(override.Column), strings.ToLower(tableName+"."+column.ColumnName))
+		return matched
+	}
+	dbType := unwrapClickhouseType(strings.ToLower(column.Type))
+	pattern := strings.ToLower(override.DbType)
+	for _, candidate := range []string{dbType, parseColumnType(column.Type).base} {
+		if matched, _ := path.Match(pattern, candidate); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyTypeOverrides 按 type_overrides 为匹配的列写入 GoType 和 GoTypeImport
+// 列注释 @json 指定的类型优先，其次是 column 规则、db_type 规则，同类规则按配置顺序取第一个匹配的
+// 指定的 Go 类型不合法时返回错误
+func (g *Generator) ApplyTypeOverrides(schemas []model.Schema) ([]model.Schema, error) {
+	columnOverrides, dbTypeOverrides := lo.FilterReject(g.configger.GenerateOption.TypeOverrides, func(override config.TypeOverride, _ int) bool {
+		return override.Column != ""
+	})
+	overrides := append(columnOverrides, dbTypeOverrides...)
+
+	for i := range schemas {
+		for j := range schemas[i].Columns {
+			column := &schemas[i].Columns[j]
+			column.GoType, column.GoTypeImport = "", ""
+			if column.JsonType != "" {
+				spec := column.JsonType
+				if column.IsNullable {
+					spec = nullableGoType(spec)
+				}
+				goType, importPath, err := parseGoType(spec)
+				if err != nil {
+					return nil, fmt.Errorf("列 %s.%s 的 @json 注解不合法: %w", schemas[i].Name, column.ColumnName, err)
+				}
+				column.GoType, column.GoTypeImport = goType, importPath
+				log.Printf("🔧 JSON 字段: %s.%s %s -> %s", schemas[i].Name, column.ColumnName, column.Type, column.GoType)
+				continue
+			}
+			override, found := lo.Find(overrides, func(override config.TypeOverride) bool {
+				return matchTypeOverride(override, schemas[i].Name, *column)
+			})
+			if !found {
+				continue
+			}
+
+			spec := override.GoType
+			if column.IsNullable {
+				spec = nullableGoType(override.GoType)
+				if override.NullableGoType != "" {
+					spec = override.NullableGoType
+				}
+			}
+			goType, importPath, err := parseGoType(spec)
+			if err != nil {
+				return nil, fmt.Errorf("type_overrides 中的 go_type 不合法: %w", err)
+			}
+			column.GoType, column.GoTypeImport = goType, importPath
+			log.Printf("🔧 类型覆盖: %s.%s %s -> %s", schemas[i].Name, column.ColumnName, column.Type, column.GoType)
+		}
+		syncIndexColumns(&schemas[i])
+	}
+	return schemas, nil
+}
+
+// syncIndexColumns 索引中的列是列定义的副本，修改列的 Go 类型后需要同步，DAO 中按索引生成的方法参数才会使用相同的类型
+func syncIndexColumns(schema *model.Schema) {
+	columnMap := lo.SliceToMap(schema.Columns, func(column model.Column) (string, model.Column) {
+		return strings.ToLower(column.ColumnName), column
+	})
+	syncIndex := func(index *model.Index) {
+		index.Columns = lo.Map(index.Columns, func(column model.Column, _ int) model.Column {
+			if synced, ok := columnMap[strings.ToLower(column.ColumnName)]; ok {
+				return synced
+			}
+			return column
+		})
+	}
+	syncIndex(&schema.PrimaryKey)
+	for i := range schema.UniqueIndex {
+		syncIndex(&schema.UniqueIndex[i])
+	}
+	for i := range schema.Indexes {
+		syncIndex(&schema.Indexes[i])
+	}
+}
+
+// typeOverrideImports 返回表结构中类型覆盖需要的导入路径，已去重排序
+func typeOverrideImports(schemas []model.Schema) []string {
+	imports := lo.Uniq(lo.FilterMap(lo.FlatMap(schemas, func(schema model.Schema, _ int) []model.Column {
+		return schema.Columns
+	}), func(column model.Column, _ int) (string, bool) {
+		return column.GoTypeImport, column.GoTypeImport != ""
+	}))
+	sort.Strings(imports)
+	return imports
+}
+
+// StdImports 返回导入路径中的标准库部分，模板中与文件原有的标准库导入放在同一组
+func StdImports(imports []string) []string {
+	return lo.Filter(imports, func(importPath string, _ int) bool {
+		return isStdImport(importPath)
+	})
+}
+
+// ThirdPartyImports 返回导入路径中的非标准库部分，模板中单独作为一组
+func ThirdPartyImports(imports []string) []string {
+	return lo.Reject(imports, func(importPath string, _ int) bool {
+		return isStdImport(importPath)
+	})
+}
+
+// isStdImport 判断是否为标准库的导入路径，标准库路径的第一段不包含点（如 encoding/json）
+func isStdImport(importPath string) bool {
+	return !strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".")
+}
+
+// imports 返回生成的文件可能需要的额外导入路径：类型覆盖的导入，以及 nullable_style 为 sql_null、generic 时的 database/sql
+// 没有用到的导入在生成后由 cleanImports 删除
+func (g *Generator) imports(schemas []model.Schema) []string {
+	imports := typeOverrideImports(schemas)
+	if style := g.configger.GenerateOption.NullableStyle; style == config.NullableStyleSqlNull || style == config.NullableStyleGeneric {
+		imports = append([]string{"database/sql"}, imports...)
+	}
+	return imports
+}
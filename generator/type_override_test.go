@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+)
+
+// TestApplyTypeOverrides 按数据库类型和 表名.列名 覆盖列的 Go 类型，并生成对应的导入
+func TestApplyTypeOverrides(t *testing.T) {
+	g, schemas := newTestGenerator(t, `CREATE TABLE t_order (
+  id bigint NOT NULL AUTO_INCREMENT,
+  amount decimal(10, 2) NOT NULL,
+  discount decimal(10, 2),
+  extra json,
+  PRIMARY KEY (id)
+);`, config.NewBuilder().
+		OutputPath(t.TempDir()).
+		DbTypeOverride("decimal", "github.com/shopspring/decimal.Decimal").
+		ColumnTypeOverride("t_order.extra", "gorm.io/datatypes.JSON"))
+
+	files := renderArtifacts(t, g, schemas)
+	assertContains(t, files, map[string][]string{
+		"po/t_order.go":      {`"github.com/shopspring/decimal"`, `"gorm.io/datatypes"`, " decimal.Decimal ", " *decimal.Decimal ", " *datatypes.JSON "},
+		"dto/t_order_dto.go": {`"github.com/shopspring/decimal"`, "func (b *TOrderDtoBuilder) WithAmount(amount decimal.Decimal)"},
+		"dao/t_order_dao.go": {`"reflect"`, "!reflect.ValueOf(queryDto.Amount).IsZero()", "queryDto.Discount != nil"},
+		"vo/t_order_vo.go":   {`"gorm.io/datatypes"`},
+	})
+	// DAO 没有用到覆盖后的类型，不应导入对应的包
+	assertNotContains(t, files, map[string][]string{
+		"dao/t_order_dao.go": {"shopspring", "datatypes"},
+	})
+	for file, content := range files {
+		if strings.Count(content, `"encoding/json"`) > 1 {
+			t.Errorf("生成的文件中存在重复的导入 [%s]\n%s", file, content)
+		}
+	}
+}
+
+// TestParseGoType 按最后一个 . 拆分导入路径和类型名，按惯例推断包名
+func TestParseGoType(t *testing.T) {
+	tests := []struct {
+		spec       string
+		goType     string
+		importPath string
+		wantErr    bool
+	}{
+		{spec: "github.com/shopspring/decimal.Decimal", goType: "decimal.Decimal", importPath: "github.com/shopspring/decimal"},
+		{spec: "*gorm.io/datatypes.JSON", goType: "*datatypes.JSON", importPath: "gorm.io/datatypes"},
+		{spec: "[]encoding/json.RawMessage", goType: "[]json.RawMessage", importPath: "encoding/json"},
+		{spec: "gopkg.in/yaml.v3.Node", goType: "yaml.Node", importPath: "gopkg.in/yaml.v3"},
+		{spec: "*gopkg.in/yaml.v3.Node", goType: "*yaml.Node", importPath: "gopkg.in/yaml.v3"},
+		{spec: "github.com/jackc/pgx/v5/pgtype.Numeric", goType: "pgtype.Numeric", importPath: "github.com/jackc/pgx/v5/pgtype"},
+		{spec: "github.com/jackc/pgx/v5.Identifier", goType: "pgx.Identifier", importPath: "github.com/jackc/pgx/v5"},
+		{spec: "github.com/mattn/go-sqlite3.SQLiteConn", goType: "sqlite3.SQLiteConn", importPath: "github.com/mattn/go-sqlite3"},
+		{spec: "datatypes.JSON", goType: "datatypes.JSON"},
+		{spec: "int64", goType: "int64"},
+		{spec: "github.com/shopspring/decimal", wantErr: true},
+		{spec: "github.com/jackc/pgx/v5", wantErr: true},
+		{spec: "example.com/pkg.Bad-Name", wantErr: true},
+	}
+	for _, tt := range tests {
+		goType, importPath, err := parseGoType(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseGoType(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if goType != tt.goType || importPath != tt.importPath {
+			t.Errorf("parseGoType(%q) = %q, %q, want %q, %q", tt.spec, goType, importPath, tt.goType, tt.importPath)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
+	"golang.org/x/tools/go/packages"
+)
+
+// maxDiagnostics 类型检查失败时最多列出的错误数量，同一个模板的错误通常在每张表中重复出现
+const maxDiagnostics = 20
+
+// generatedFile 已生成、尚未写入磁盘的文件
+type generatedFile struct {
+	path      string   // 输出文件路径
+	kind      string   // 产物类型
+	template  string   // 生成该文件的模板路径
+	tables    []string // 生成该文件的表
+	content   []byte   // 格式化后的代码，格式化失败时为模板的原始输出
+	formatErr error    // 格式化失败的原因
+}
+
+// addFile 删除多余的导入并格式化生成的代码，暂存到待写入的文件列表中
+// 格式化失败时保留原始输出，由 WriteFiles 报告错误
+func (g *Generator) addFile(kind, templatePath string, schemas []model.Schema, filePath string, code []byte) {
+	file := generatedFile{
+		path:     filePath,
+		kind:     kind,
+		template: templatePath,
+		tables: lo.Map(schemas, func(schema model.Schema, _ int) string {
+			return schema.Name
+		}),
+		content: code,
+	}
+	formattedCode, err := format.Source(cleanImports(code))
+	if err != nil {
+		file.formatErr = err
+	} else {
+		file.content = formattedCode
+	}
+	g.files = append(g.files, file)
+}
+
+// WriteFiles 检查暂存的生成代码，全部通过后写入输出目录
+// 生成的代码无法格式化或没有通过类型检查时不写入任何文件，返回的错误中列出出错的文件、位置、模板和表
+// 开启 dry_run 或 check 时只与已有的文件比较，不写入任何文件（见 diffFiles）
+func (g *Generator) WriteFiles() error {
+	files := g.files
+	g.files = nil
+	if err := g.verify(files); err != nil {
+		return err
+	}
+	if option := g.configger.GenerateOption; option.DryRun || option.Check {
+		return g.diffFiles(files)
+	}
+
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file.path), 0755); err != nil {
+			return fmt.Errorf("创建输出目录失败: %w", err)
+		}
+		if err := os.WriteFile(file.path, file.content, 0644); err != nil {
+			return fmt.Errorf("写入输出文件失败: %w", err)
+		}
+		log.Printf("成功生成文件: %s\n", file.path)
+	}
+	return nil
+}
+
+// verify 检查生成的代码能否编译：先检查语法，再按包在内存中进行类型检查
+// 生成的包之间的引用直接使用内存中的代码，标准库和第三方依赖从 output_path 所在的模块加载（见 loadDependencies），不会修改该模块的 go.mod、go.sum
+// 依赖无法加载时（如目标模块没有引入 gorm）只打印警告，直接或间接导入了这些依赖的包的类型错误也只计入警告，不影响其他包的检查
+// 无法确定 output_path 的导入路径（没有配置 module_path，也没有 go.mod）时返回错误，不写入无法保证能编译的代码
+func (g *Generator) verify(files []generatedFile) error {
+	var diagnostics []string
+	for _, file := range files {
+		if file.formatErr != nil {
+			diagnostics = append(diagnostics, g.diagnostic(file, file.formatErr.Error()))
+		}
+	}
+	if len(diagnostics) > 0 {
+		return fmt.Errorf("生成的代码存在语法错误，未写入任何文件:\n%s", strings.Join(diagnostics, "\n"))
+	}
+
+	// 不知道 output_path 的导入路径时，生成的包之间的引用无法解析，不能保证写入的代码可以编译
+	if g.outputImportPath == "" {
+		return fmt.Errorf("无法确定 output_path 的导入路径，生成的代码无法进行类型检查，未写入任何文件: 请配置 module_path，或在 output_path 及其上级目录中提供 go.mod")
+	}
+
+	log.Println("🔎 开始对生成的代码进行类型检查...")
+	fset := token.NewFileSet()
+	fileMap := make(map[string]generatedFile)
+	generatedPackages := make(map[string]*generatedPackage)
+	for _, file := range files {
+		astFile, err := parser.ParseFile(fset, file.path, file.content, parser.AllErrors)
+		if err != nil {
+			return fmt.Errorf("解析生成的代码失败 [%s]: %w", file.path, err)
+		}
+		fileMap[file.path] = file
+		importPath := g.fileImportPath(file.path)
+		if generatedPackages[importPath] == nil {
+			generatedPackages[importPath] = &generatedPackage{}
+		}
+		generatedPackages[importPath].files = append(generatedPackages[importPath].files, astFile)
+	}
+
+	dependencies, brokenImports := loadDependencies(fset, existingDir(g.configger.GenerateOption.OutputPath), dependencyPaths(generatedPackages))
+	checker := newGeneratedImporter(fset, generatedPackages, dependencies, brokenImports)
+	for _, importPath := range lo.Keys(generatedPackages) {
+		checker.check(importPath)
+	}
+
+	brokenImportPaths := lo.Keys(checker.brokenImports)
+	sort.Strings(brokenImportPaths)
+	for _, importPath := range brokenImportPaths {
+		log.Printf("⚠️ 无法加载依赖 %s，跳过与其相关的类型检查（请确认 output_path 所在的模块已引入该依赖）: %v", importPath, checker.brokenImports[importPath])
+	}
+	skippedPackages := lo.Keys(checker.skippedErrors)
+	sort.Strings(skippedPackages)
+	for _, importPath := range skippedPackages {
+		log.Printf("⚠️ 包 %s 依赖无法加载的包，忽略其中 %d 个类型错误", importPath, checker.skippedErrors[importPath])
+	}
+
+	// 按文件和位置排序，同一个包中的错误由类型检查器按出现的顺序报告，跨包时顺序不固定
+	sort.SliceStable(checker.errors, func(a, b int) bool {
+		positionA, positionB := fset.Position(checker.errors[a].Pos), fset.Position(checker.errors[b].Pos)
+		if positionA.Filename != positionB.Filename {
+			return positionA.Filename < positionB.Filename
+		}
+		return positionA.Offset < positionB.Offset
+	})
+	for i, typeErr := range checker.errors {
+		if i == maxDiagnostics {
+			diagnostics = append(diagnostics, fmt.Sprintf("  ... 还有 %d 个错误", len(checker.errors)-maxDiagnostics))
+			break
+		}
+		position := fset.Position(typeErr.Pos)
+		message := fmt.Sprintf("%d:%d: %s", position.Line, position.Column, typeErr.Msg)
+		diagnostics = append(diagnostics, g.diagnostic(fileMap[position.Filename], message))
+	}
+	if len(diagnostics) > 0 {
+		return fmt.Errorf("生成的代码没有通过类型检查，未写入任何文件:\n%s", strings.Join(diagnostics, "\n"))
+	}
+	if len(skippedPackages) > 0 {
+		log.Printf("✅ 类型检查完成，共 %d 个包、%d 个文件，其中 %d 个包因依赖无法加载没有完整检查", len(generatedPackages), len(files), len(skippedPackages))
+		return nil
+	}
+	log.Printf("✅ 类型检查通过，共 %d 个包、%d 个文件", len(generatedPackages), len(files))
+	return nil
+}
+
+// diagnostic 格式化一条错误信息，附带出错的文件、生成该文件的模板和表
+// 示例: dao/t_user_dao.go:12:5: undefined: foo（模板 template/dao.template，表 t_user）
+func (g *Generator) diagnostic(file generatedFile, message string) string {
+	source := "模板 " + file.template
+	if len(file.tables) > 0 {
+		source += "，表 " + strings.Join(file.tables, "、")
+	}
+	return fmt.Sprintf("  - %s:%s（%s）", filepath.ToSlash(g.relPath(file.path)), strings.TrimPrefix(message, file.path+":"), source)
+}
+
+// fileImportPath 返回生成的文件所在包的导入路径
+func (g *Generator) fileImportPath(filePath string) string {
+	relDir, err := filepath.Rel(g.configger.GenerateOption.OutputPath, filepath.Dir(filePath))
+	if err != nil {
+		return filepath.Dir(filePath)
+	}
+	return path.Join(g.outputImportPath, filepath.ToSlash(relDir))
+}
+
+// existingDir 返回路径本身或最近的已存在的上级目录，输出目录在第一次生成时可能还不存在
+func existingDir(dir string) string {
+	dir, _ = filepath.Abs(dir)
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		if filepath.Dir(dir) == dir {
+			return dir
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// dependencyPaths 返回生成的代码导入的、不属于生成的包的导入路径（标准库和第三方依赖），按导入路径排序
+func dependencyPaths(generatedPackages map[string]*generatedPackage) []string {
+	var importPaths []string
+	for _, generated := range generatedPackages {
+		for _, file := range generated.files {
+			for _, spec := range file.Imports {
+				importPath, err := strconv.Unquote(spec.Path.Value)
+				if err == nil && generatedPackages[importPath] == nil {
+					importPaths = append(importPaths, importPath)
+				}
+			}
+		}
+	}
+	importPaths = lo.Uniq(importPaths)
+	sort.Strings(importPaths)
+	return importPaths
+}
+
+// loadDependencies 在 dir 所在的模块中加载生成的代码导入的标准库和第三方包，返回加载到的包和无法加载的包（及原因），均按导入路径索引
+// 依赖按目标模块的 go.mod 解析，使用 -mod=readonly 禁止 go 命令修改目标模块的 go.mod、go.sum，缺少的依赖只作为无法加载的包返回
+// 类型信息读取编译缓存中的导出数据，不需要每次从源码检查 gorm 等依赖
+// 所有依赖在一次加载中读取，标准库和第三方依赖中的 context.Context、time.Time 等类型才是同一个对象
+func loadDependencies(fset *token.FileSet, dir string, importPaths []string) (map[string]*types.Package, map[string]error) {
+	dependencies := make(map[string]*types.Package)
+	brokenImports := make(map[string]error)
+	if len(importPaths) == 0 {
+		return dependencies, brokenImports
+	}
+
+	loaded, err := packages.Load(&packages.Config{
+		Mode:       packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedTypes,
+		Dir:        dir,
+		Fset:       fset,
+		BuildFlags: []string{"-mod=readonly"},
+	}, importPaths...)
+	if err != nil {
+		for _, importPath := range importPaths {
+			brokenImports[importPath] = err
+		}
+		return dependencies, brokenImports
+	}
+	for _, pkg := range loaded {
+		// 包本身或其依赖加载失败时，导出数据不完整，按无法加载处理
+		var loadErrors []packages.Error
+		packages.Visit([]*packages.Package{pkg}, nil, func(dependency *packages.Package) {
+			loadErrors = append(loadErrors, dependency.Errors...)
+		})
+		switch {
+		case len(loadErrors) > 0:
+			brokenImports[pkg.PkgPath] = loadErrors[0]
+		case pkg.Types == nil || pkg.IllTyped:
+			brokenImports[pkg.PkgPath] = fmt.Errorf("类型信息不完整")
+		default:
+			dependencies[pkg.PkgPath] = pkg.Types
+		}
+	}
+	for _, importPath := range importPaths {
+		if dependencies[importPath] == nil && brokenImports[importPath] == nil {
+			brokenImports[importPath] = fmt.Errorf("go list 没有返回该包")
+		}
+	}
+	return dependencies, brokenImports
+}
+
+// generatedPackage 内存中的一个生成包
+type generatedPackage struct {
+	files    []*ast.File
+	pkg      *types.Package
+	checking bool
+	degraded bool // 直接或间接导入了无法加载的依赖，类型错误可能由缺失的依赖引起
+}
+
+// generatedImporter 类型检查使用的导入器
+// 生成的包直接在内存中检查，标准库和第三方依赖使用 loadDependencies 预先加载的包
+type generatedImporter struct {
+	fset          *token.FileSet
+	packages      map[string]*generatedPackage
+	dependencies  map[string]*types.Package
+	brokenImports map[string]error
+	errors        []types.Error
+	skippedErrors map[string]int // 依赖无法加载的包中被忽略的类型错误数量
+}
+
+func newGeneratedImporter(fset *token.FileSet, generatedPackages map[string]*generatedPackage, dependencies map[string]*types.Package, brokenImports map[string]error) *generatedImporter {
+	return &generatedImporter{
+		fset:          fset,
+		packages:      generatedPackages,
+		dependencies:  dependencies,
+		brokenImports: brokenImports,
+		skippedErrors: make(map[string]int),
+	}
+}
+
+// Import 实现 types.Importer
+func (i *generatedImporter) Import(importPath string) (*types.Package, error) {
+	if _, ok := i.packages[importPath]; ok {
+		return i.check(importPath)
+	}
+	if pkg, ok := i.dependencies[importPath]; ok {
+		return pkg, nil
+	}
+	err, ok := i.brokenImports[importPath]
+	if !ok {
+		err = fmt.Errorf("没有加载依赖 %s", importPath)
+		i.brokenImports[importPath] = err
+	}
+	return nil, err
+}
+
+// check 对内存中的生成包进行类型检查，每个包只检查一次
+// 包直接或间接导入了无法加载的依赖时，缺失的类型会引起大量无关的错误（如嵌入的 *gorm.DB 没有 WithContext 方法），这些错误只计数不报告
+func (i *generatedImporter) check(importPath string) (*types.Package, error) {
+	generated := i.packages[importPath]
+	if generated.checking {
+		return nil, fmt.Errorf("生成的包之间存在循环导入: %s", importPath)
+	}
+	if generated.pkg != nil {
+		return generated.pkg, nil
+	}
+
+	generated.checking = true
+	var typeErrors []types.Error
+	config := types.Config{
+		Importer: i,
+		Error: func(err error) {
+			var typeErr types.Error
+			// 无法加载的依赖已记录在 brokenImports 中，不再重复报告
+			if errors.As(err, &typeErr) && !strings.HasPrefix(typeErr.Msg, "could not import") {
+				typeErrors = append(typeErrors, typeErr)
+			}
+		},
+	}
+	generated.pkg, _ = config.Check(importPath, i.fset, generated.files, nil)
+	generated.checking = false
+
+	generated.degraded = lo.SomeBy(generated.files, func(file *ast.File) bool {
+		return lo.SomeBy(file.Imports, func(spec *ast.ImportSpec) bool {
+			dependency, _ := strconv.Unquote(spec.Path.Value)
+			_, broken := i.brokenImports[dependency]
+			return broken || (i.packages[dependency] != nil && i.packages[dependency].degraded)
+		})
+	})
+	if generated.degraded {
+		if len(typeErrors) > 0 {
+			i.skippedErrors[importPath] += len(typeErrors)
+		}
+	} else {
+		i.errors = append(i.errors, typeErrors...)
+	}
+	return generated.pkg, nil
+}
@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/LingoJack/model_infrax/config"
+)
+
+// TestWriteFilesTypeCheck 生成的代码没有通过类型检查时报告出错的模板和表，并且不写入任何文件
+func TestWriteFilesTypeCheck(t *testing.T) {
+	const sql = `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_name varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);`
+	registerTestTemplateSet(t, TemplateSet{
+		Name: "test-broken",
+		FS: fstest.MapFS{
+			"po.template":  {Data: []byte("package {{ .PoPackageName }}\n{{ range .Schemas }}\ntype {{ .Name | EntityName }} struct{ ID int64 }\n{{ end }}")},
+			"dao.template": {Data: []byte("package {{ .DaoPackageName }}\n{{ range .Schemas }}\nfunc Get{{ .Name | EntityName }}ID() string { return {{ .Name | EntityName }}ID }\n{{ end }}")},
+		},
+		Artifacts: []Artifact{
+			{Kind: ArtifactPo, Template: "po.template", FileName: "%s.go"},
+			{Kind: ArtifactDao, Template: "dao.template", FileName: "%s_dao.go"},
+		},
+	})
+	outputPath := filepath.Join(t.TempDir(), "output")
+	g, schemas := newTestGenerator(t, sql, config.NewBuilder().OutputPath(outputPath).UseFramework("test-broken").ModulePath("example.com/app/model"))
+	renderArtifacts(t, g, schemas)
+
+	err := g.WriteFiles()
+	if err == nil {
+		t.Fatalf("生成的代码没有通过类型检查时应返回错误")
+	}
+	for _, expected := range []string{"类型检查", "dao/t_user_dao.go:3:", "undefined: TUserID", "模板 dao.template", "表 t_user"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("类型检查的错误信息缺少内容: %s\n%v", expected, err)
+		}
+	}
+	if _, err = os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Errorf("类型检查失败时不应写入任何文件（包括通过检查的 PO）")
+	}
+
+	// 模板输出无法解析时同样不写入任何文件，不再写入未格式化的代码
+	registerTestTemplateSet(t, TemplateSet{
+		Name: "test-broken",
+		FS:   fstest.MapFS{"syntax.template": {Data: []byte("package {{ .PoPackageName }}\n{{ range .Schemas }}\nfunc {{ .Name | EntityName }}( {\n{{ end }}")}},
+		Artifacts: []Artifact{
+			{Kind: ArtifactPo, Template: "syntax.template", FileName: "%s.go"},
+		},
+	})
+	g, schemas = newTestGenerator(t, sql, config.NewBuilder().OutputPath(outputPath).UseFramework("test-broken").ModulePath("example.com/app/model"))
+	if err = g.GenerateArtifact(g.Artifacts()[0], schemas); err != nil {
+		t.Fatalf("生成代码失败: %v", err)
+	}
+	if err = g.WriteFiles(); err == nil || !strings.Contains(err.Error(), "语法错误") || !strings.Contains(err.Error(), "模板 syntax.template，表 t_user") {
+		t.Errorf("生成的代码存在语法错误时应返回错误: %v", err)
+	}
+	if _, err = os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Errorf("生成的代码存在语法错误时不应写入任何文件")
+	}
+}
+
+// TestWriteFilesMissingDependency output_path 所在的模块没有引入 gorm 时只打印警告，照常写入生成的代码，且不修改该模块
+func TestWriteFilesMissingDependency(t *testing.T) {
+	moduleDir := t.TempDir()
+	// 引入了 gorm 但没有 go.sum，gorm 无法加载
+	goMod := "module example.com/app\n\ngo 1.22\n\nrequire gorm.io/gorm v1.31.1\n"
+	if err := os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatalf("写入 go.mod 失败: %v", err)
+	}
+	outputPath := filepath.Join(moduleDir, "model")
+	// 禁止下载依赖，保证 gorm 在任何环境下都无法加载；-mod=mod 时 go 命令会补全 go.mod、go.sum，类型检查不能使用
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	g, schemas := newTestGenerator(t, `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_name varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);`, config.NewBuilder().OutputPath(outputPath))
+	logs := captureLogs(t)
+	renderArtifacts(t, g, schemas)
+
+	if err := g.WriteFiles(); err != nil {
+		t.Fatalf("依赖无法加载时不应返回错误: %v", err)
+	}
+	for _, expected := range []string{"无法加载依赖 gorm.io/gorm", "包 example.com/app/model/dao 依赖无法加载的包"} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("日志缺少警告: %s\n%s", expected, logs.String())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "dao", "t_user_dao.go")); err != nil {
+		t.Errorf("依赖无法加载时应照常写入生成的代码: %v", err)
+	}
+	// 类型检查不能修改目标模块
+	if content, _ := os.ReadFile(filepath.Join(moduleDir, "go.mod")); string(content) != goMod {
+		t.Errorf("类型检查不应修改 go.mod:\n%s", content)
+	}
+	if _, err := os.Stat(filepath.Join(moduleDir, "go.sum")); !os.IsNotExist(err) {
+		t.Errorf("类型检查不应创建 go.sum")
+	}
+}
+
+// TestWriteFilesWithoutModule 没有配置 module_path，output_path 及其上级目录中也没有 go.mod 时无法进行类型检查，返回错误且不写入任何文件
+func TestWriteFilesWithoutModule(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "output")
+	g, schemas := newTestGenerator(t, `CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);`, config.NewBuilder().OutputPath(outputPath))
+	renderArtifacts(t, g, schemas)
+
+	if err := g.WriteFiles(); err == nil || !strings.Contains(err.Error(), "module_path") {
+		t.Errorf("无法确定 output_path 的导入路径时应返回错误并提示配置 module_path: %v", err)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Errorf("无法进行类型检查时不应写入任何文件")
+	}
+}
@@ -17,6 +17,20 @@ type Column struct {
 	IsIndexed       bool    // 是否有索引
 	IsUnique        bool    // 是否唯一索引
 	IsPrimaryKey    bool    // 是否主键
+
+	CharacterMaxLength *int64   // 字符类型的最大长度（如 varchar(128) 为 128），非字符类型为nil
+	NumericPrecision   *int64   // 数值类型的精度（如 decimal(10,2) 为 10），非数值类型为nil
+	NumericScale       *int64   // 数值类型的小数位数（如 decimal(10,2) 为 2），非数值类型为nil
+	OrdinalPosition    int      // 列在表中的位置（从1开始），0表示未知
+	OnUpdate           *string  // ON UPDATE 表达式（如 CURRENT_TIMESTAMP），没有时为nil
+	EnumValues         []string // ENUM、SET 列允许的取值（按定义顺序），或由注释 @enum 指定的取值，其他列为nil
+	FieldName          string   // 由注释 @name 指定的字段名，为空时使用列名的 PascalCase 形式
+	JsonType           string   // 由注释 @json 指定的 Go 类型（可带导入路径），以 JSON 序列化存储，没有时为空
+	Sensitive          bool     // 注释中标记了 @sensitive，PO 序列化为 JSON 时不输出，VO 中不生成该字段
+
+	GoType       string // 由 type_overrides 指定的 Go 类型（已按是否可空处理），为空时按数据库类型映射
+	GoTypeImport string // GoType 需要的导入路径（如 github.com/shopspring/decimal），不需要导入时为空
+	EnumTypeName string // ENUM、SET 列生成的枚举类型名（如 TOrderStatus），定义在 PO 包中，没有时为空
 }
 
 func (f Column) Json() string {
@@ -33,4 +47,4 @@ func (f Column) JsonIndent() string {
 		return fmt.Sprintf(`{"error": "%s"}`, err.Error())
 	}
 	return string(byts)
-}
\ No newline at end of file
+}
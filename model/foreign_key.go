@@ -0,0 +1,29 @@
+package model
+
+// ForeignKey 外键约束
+type ForeignKey struct {
+	Name              string   // 约束名称
+	Columns           []string // 本表中的外键列，按约束中的顺序排列
+	ReferencedTable   string   // 被引用的表
+	ReferencedColumns []string // 被引用表中的列，与 Columns 一一对应
+	OnDelete          string   // 删除时的动作，如 CASCADE、SET NULL，未声明时为空
+	OnUpdate          string   // 更新时的动作，未声明时为空
+}
+
+// 关联关系类型
+const (
+	RelationBelongsTo = "belongs_to" // 本表通过外键引用另一张表
+	RelationHasMany   = "has_many"   // 另一张表通过外键引用本表
+)
+
+// Relation 由外键推导出的表间关联关系，用于生成 GORM 关联字段和预加载方法
+type Relation struct {
+	Kind       string // 关联类型: belongs_to 或 has_many
+	Name       string // 关联名称（下划线风格），生成的字段名为其 PascalCase 形式
+	Table      string // 关联的另一张表
+	ForeignKey string // 外键列（belongs_to 时在本表，has_many 时在关联表）
+	References string // 被引用的列（belongs_to 时在关联表，has_many 时在本表）
+
+	ForeignKeyField string // 外键列对应的结构体字段名
+	ReferencesField string // 被引用的列对应的结构体字段名
+}
@@ -3,4 +3,15 @@ package model
 type Index struct {
 	IndexName string
 	Columns   []Column
+	IsUnique  bool        // 是否唯一索引（主键也是唯一索引）
+	IndexType string      // 索引类型，如 BTREE、HASH、FULLTEXT，未知时为空
+	Parts     []IndexPart // 索引中每一列的元数据，与 Columns 一一对应
+}
+
+// IndexPart 索引中单个列的元数据
+type IndexPart struct {
+	ColumnName string // 列名
+	Seq        int    // 列在索引中的序号（从1开始）
+	SubPart    *int64 // 前缀索引的长度（如 name(16) 为 16），索引整列时为nil
+	Direction  string // 排序方向: ASC 或 DESC
 }
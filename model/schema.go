@@ -7,11 +7,20 @@ import (
 
 type Schema struct {
 	Name        string
+	Namespace   string // 表所属的 schema（如 postgres 的非 public schema），为空表示使用连接默认值
 	Columns     []Column
 	Comment     string
-	PrimaryKey  Index
-	UniqueIndex []Index
-	Indexes     []Index
+	PrimaryKey  Index        // 主键，统一命名为 PRIMARY，表没有主键时 Columns 为空
+	UniqueIndex []Index      // 唯一索引，不包含主键
+	Indexes     []Index      // 普通索引，不包含主键和唯一索引，每个索引只出现在三者之一中；ClickHouse 表的排序键也作为普通索引
+	ForeignKeys []ForeignKey // 外键约束
+	Relations   []Relation   // 由外键推导出的关联关系，在过滤表之后计算，只包含参与生成的表
+	EntityName  string       // 由表注释 @name 指定的结构体名，为空时由表名推导
+
+	// 以下字段仅 ClickHouse 表有值
+	Engine       string // 表引擎，如 MergeTree、ReplacingMergeTree
+	SortingKey   string // ORDER BY 排序键表达式
+	PartitionKey string // PARTITION BY 分区键表达式
 }
 
 func (t Schema) Json() string {
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
+)
+
+// annotationRegexp 匹配表、列注释中的注解，如 @enum(robot,brain)、@sensitive
+// 注解前必须是注释开头或空白字符，避免误匹配注释中的邮箱等内容；未识别的注解保留在注释中
+var annotationRegexp = regexp.MustCompile(`(^|\s)@(enum|json|sensitive|ignore|name)\b(?:\(([^)]*)\))?`)
+
+// annotations 从注释中解析出的注解
+type annotations struct {
+	enumValues []string // @enum(a,b)：枚举取值
+	jsonType   string   // @json(pkg.Type)：以 JSON 存储的 Go 类型
+	sensitive  bool     // @sensitive：敏感字段
+	ignore     bool     // @ignore：不生成代码
+	name       string   // @name(FooBar)：结构体名或字段名
+}
+
+// parseAnnotations 解析注释中的注解，返回去掉注解后的注释
+// 示例:
+//   - "执行器 @enum(robot, brain)" -> "执行器", enumValues: [robot brain]
+//   - "扩展信息 @json(github.com/foo/bar.Extra)" -> "扩展信息", jsonType: github.com/foo/bar.Extra
+func parseAnnotations(comment string) (string, annotations) {
+	var result annotations
+	cleaned := annotationRegexp.ReplaceAllStringFunc(comment, func(match string) string {
+		groups := annotationRegexp.FindStringSubmatch(match)
+		argument := strings.TrimSpace(groups[3])
+		switch groups[2] {
+		case "enum":
+			result.enumValues = lo.Filter(lo.Map(strings.Split(argument, ","), func(value string, _ int) string {
+				return strings.TrimSpace(value)
+			}), func(value string, _ int) bool {
+				return value != ""
+			})
+		case "json":
+			result.jsonType = argument
+		case "sensitive":
+			result.sensitive = true
+		case "ignore":
+			result.ignore = true
+		case "name":
+			result.name = argument
+		}
+		return groups[1]
+	})
+	return strings.TrimSpace(cleaned), result
+}
+
+// applyAnnotations 各解析器 Parse 的公共后处理，将表、列注释中的注解写入表结构，并从注释中去掉注解
+//   - 表注释: @ignore 跳过整张表，@name 指定结构体名
+//   - 列注释: @ignore 跳过该列（同时去掉包含该列的索引和外键，主键列不能跳过），
+//     @enum 指定枚举取值，@json 指定以 JSON 存储的 Go 类型，@sensitive 标记敏感字段，@name 指定字段名
+func applyAnnotations(schemas []model.Schema) []model.Schema {
+	result := make([]model.Schema, 0, len(schemas))
+	for _, schema := range schemas {
+		comment, tableAnnotations := parseAnnotations(schema.Comment)
+		if tableAnnotations.ignore {
+			log.Printf("⏭️ 表 %s 的注释中标记了 @ignore，跳过", schema.Name)
+			continue
+		}
+		schema.Comment = comment
+		schema.EntityName = tableAnnotations.name
+
+		ignoredColumns := make(map[string]bool)
+		columns := make([]model.Column, 0, len(schema.Columns))
+		for _, column := range schema.Columns {
+			comment, columnAnnotations := parseAnnotations(column.Comment)
+			column.Comment = comment
+			if columnAnnotations.ignore && column.IsPrimaryKey {
+				log.Printf("⚠️ 主键列 %s.%s 不能标记 @ignore，已忽略该注解", schema.Name, column.ColumnName)
+			} else if columnAnnotations.ignore {
+				ignoredColumns[strings.ToLower(column.ColumnName)] = true
+				continue
+			}
+			if len(columnAnnotations.enumValues) > 0 && len(column.EnumValues) == 0 {
+				column.EnumValues = columnAnnotations.enumValues
+			}
+			column.JsonType = columnAnnotations.jsonType
+			column.Sensitive = columnAnnotations.sensitive
+			column.FieldName = columnAnnotations.name
+			columns = append(columns, column)
+		}
+		schema.Columns = columns
+
+		// 索引中的列是列定义的副本，需要同步注解，并去掉包含被跳过的列的索引
+		columnMap := lo.SliceToMap(columns, func(column model.Column) (string, model.Column) {
+			return strings.ToLower(column.ColumnName), column
+		})
+		syncIndex := func(index model.Index) (model.Index, bool) {
+			index.Columns = append([]model.Column(nil), index.Columns...)
+			for k, column := range index.Columns {
+				if ignoredColumns[strings.ToLower(column.ColumnName)] {
+					return index, false
+				}
+				if synced, ok := columnMap[strings.ToLower(column.ColumnName)]; ok {
+					index.Columns[k] = synced
+				}
+			}
+			return index, true
+		}
+		schema.PrimaryKey, _ = syncIndex(schema.PrimaryKey)
+		schema.UniqueIndex = lo.FilterMap(schema.UniqueIndex, func(index model.Index, _ int) (model.Index, bool) {
+			return syncIndex(index)
+		})
+		schema.Indexes = lo.FilterMap(schema.Indexes, func(index model.Index, _ int) (model.Index, bool) {
+			return syncIndex(index)
+		})
+		schema.ForeignKeys = lo.Reject(schema.ForeignKeys, func(foreignKey model.ForeignKey, _ int) bool {
+			return lo.SomeBy(foreignKey.Columns, func(columnName string) bool {
+				return ignoredColumns[strings.ToLower(columnName)]
+			})
+		})
+		if len(ignoredColumns) > 0 {
+			log.Printf("⏭️ 表 %s 中标记了 @ignore 的 %d 列已跳过", schema.Name, len(ignoredColumns))
+		}
+		result = append(result, schema)
+	}
+	return result
+}
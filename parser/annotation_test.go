@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	comment, parsed := parseAnnotations("执行器 @enum(robot, brain,) @sensitive")
+	if comment != "执行器" || fmt.Sprint(parsed.enumValues) != "[robot brain]" || !parsed.sensitive {
+		t.Errorf("@enum、@sensitive 解析不正确: %q %+v", comment, parsed)
+	}
+	comment, parsed = parseAnnotations("@json(github.com/foo/bar.Extra) 扩展信息 @name(ExtraInfo)")
+	if comment != "扩展信息" || parsed.jsonType != "github.com/foo/bar.Extra" || parsed.name != "ExtraInfo" {
+		t.Errorf("@json、@name 解析不正确: %q %+v", comment, parsed)
+	}
+	// 邮箱、未识别的注解不是注解，保留在注释中
+	comment, parsed = parseAnnotations("联系人 admin@ignore.com @todo 待补充")
+	if comment != "联系人 admin@ignore.com @todo 待补充" || parsed.ignore {
+		t.Errorf("不应解析非注解的内容: %q %+v", comment, parsed)
+	}
+}
+
+func TestApplyAnnotations(t *testing.T) {
+	id := model.Column{ColumnName: "id", IsPrimaryKey: true, Comment: "主键 @ignore"}
+	legacy := model.Column{ColumnName: "legacy", IsIndexed: true, Comment: "@ignore"}
+	status := model.Column{ColumnName: "status", IsIndexed: true, Comment: "状态 @enum(on,off) @name(State)"}
+	userID := model.Column{ColumnName: "user_id", Comment: "@ignore"}
+	schemas := []model.Schema{
+		{
+			Name:        "t_order",
+			Comment:     "订单表 @name(Order)",
+			Columns:     []model.Column{id, legacy, status, userID},
+			PrimaryKey:  model.Index{IndexName: "PRIMARY", Columns: []model.Column{id}},
+			Indexes:     []model.Index{{IndexName: "idx_legacy", Columns: []model.Column{legacy, status}}, {IndexName: "idx_status", Columns: []model.Column{status}}},
+			ForeignKeys: []model.ForeignKey{{Name: "fk_user", Columns: []string{"user_id"}, ReferencedTable: "t_user"}},
+		},
+		{Name: "t_order_tmp", Comment: "@ignore"},
+	}
+
+	schemas = applyAnnotations(schemas)
+	if len(schemas) != 1 {
+		t.Fatalf("标记 @ignore 的表应被跳过: %d", len(schemas))
+	}
+	order := schemas[0]
+	if order.EntityName != "Order" || order.Comment != "订单表" {
+		t.Errorf("表注解解析不正确: %s %s", order.EntityName, order.Comment)
+	}
+	columnNames := lo.Map(order.Columns, func(column model.Column, _ int) string {
+		return column.ColumnName
+	})
+	if fmt.Sprint(columnNames) != "[id status]" {
+		t.Errorf("标记 @ignore 的列应被跳过，主键除外: %v", columnNames)
+	}
+	if len(order.Indexes) != 1 || len(order.ForeignKeys) != 0 {
+		t.Errorf("包含被跳过的列的索引和外键应被去掉: %+v %+v", order.Indexes, order.ForeignKeys)
+	}
+	if indexed := order.Indexes[0].Columns[0]; indexed.FieldName != "State" || fmt.Sprint(indexed.EnumValues) != "[on off]" || indexed.Comment != "状态" {
+		t.Errorf("索引中的列应同步注解: %+v", indexed)
+	}
+}
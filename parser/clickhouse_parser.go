@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
+	"gorm.io/driver/clickhouse"
+	"gorm.io/gorm"
+)
+
+// clickhouseSortingKeyIndexName ClickHouse 排序键对应的索引名
+// ClickHouse 没有唯一约束，排序键（稀疏主键）是唯一能加速查询的"索引"
+const clickhouseSortingKeyIndexName = "sorting_key"
+
+// ClickhouseParser ClickHouse 解析器，通过 system.tables / system.columns 读取表结构
+type ClickhouseParser struct {
+	db        *gorm.DB
+	configger *config.Configger
+}
+
+// NewClickhouseParser 创建 ClickHouse 解析器
+func NewClickhouseParser(cfg *config.Configger) (*ClickhouseParser, error) {
+	dsn, err := clickhouseDSN(cfg.GenerateConfig)
+	if err != nil {
+		return nil, fmt.Errorf("构建连接串失败: %w", err)
+	}
+
+	db, err := gorm.Open(clickhouse.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", redactPassword(err, cfg.GenerateConfig))
+	}
+
+	return &ClickhouseParser{
+		db:        db,
+		configger: cfg,
+	}, nil
+}
+
+// clickhouseTable system.tables 中的表信息
+type clickhouseTable struct {
+	Name         string `gorm:"column:name"`          // 表名
+	Engine       string `gorm:"column:engine"`        // 表引擎
+	SortingKey   string `gorm:"column:sorting_key"`   // ORDER BY 表达式
+	PartitionKey string `gorm:"column:partition_key"` // PARTITION BY 表达式
+	Comment      string `gorm:"column:comment"`       // 表注释
+}
+
+// clickhouseColumn system.columns 中的列信息
+type clickhouseColumn struct {
+	Table             string `gorm:"column:table"`              // 表名
+	Name              string `gorm:"column:name"`               // 列名
+	Type              string `gorm:"column:type"`               // 列类型（如 Nullable(String)、LowCardinality(String)）
+	DefaultKind       string `gorm:"column:default_kind"`       // 默认值类型：DEFAULT、MATERIALIZED、ALIAS 或空
+	DefaultExpression string `gorm:"column:default_expression"` // 默认值表达式
+	Comment           string `gorm:"column:comment"`            // 列注释
+	IsInSortingKey    uint8  `gorm:"column:is_in_sorting_key"`  // 是否在排序键中
+	Position          uint64 `gorm:"column:position"`           // 列在表中的位置（从1开始）
+}
+
+const clickhouseTablesSQL = `
+select name, engine, sorting_key, partition_key, comment
+from system.tables
+where database = ?
+  and is_temporary = 0
+  and engine not like '%View'
+  and engine not in ('Dictionary', 'Distributed')
+order by name`
+
+const clickhouseColumnsSQL = `
+select table, name, type, default_kind, default_expression, comment, is_in_sorting_key, position
+from system.columns
+where database = ?
+order by table, position`
+
+// Parse 解析数据库下所有表的结构
+// 列信息通过一次批量查询获取，再按表名分组
+func (p *ClickhouseParser) Parse() (schemas []model.Schema, err error) {
+	databaseName := p.configger.GenerateConfig.DatabaseName
+
+	var tables []clickhouseTable
+	if err = p.db.Raw(clickhouseTablesSQL, databaseName).Scan(&tables).Error; err != nil {
+		return nil, fmt.Errorf("查询数据库表失败: %w", err)
+	}
+
+	var columns []clickhouseColumn
+	if err = p.db.Raw(clickhouseColumnsSQL, databaseName).Scan(&columns).Error; err != nil {
+		return nil, fmt.Errorf("查询表字段失败: %w", err)
+	}
+
+	table2Columns := lo.GroupBy(columns, func(column clickhouseColumn) string {
+		return column.Table
+	})
+
+	for _, table := range tables {
+		schemas = append(schemas, buildClickhouseSchema(table, table2Columns[table.Name]))
+	}
+
+	return applyAnnotations(schemas), nil
+}
+
+// buildClickhouseSchema 将 system 表中的行转换为表结构
+func buildClickhouseSchema(table clickhouseTable, fields []clickhouseColumn) model.Schema {
+	schema := model.Schema{
+		Name:         table.Name,
+		Comment:      table.Comment,
+		Engine:       table.Engine,
+		SortingKey:   table.SortingKey,
+		PartitionKey: table.PartitionKey,
+	}
+
+	columnIndexMap := make(map[string]int)
+	for _, field := range fields {
+		// MATERIALIZED / ALIAS 列由数据库计算，不能写入，生成到 PO 中会导致插入失败
+		if field.DefaultKind == "MATERIALIZED" || field.DefaultKind == "ALIAS" {
+			log.Printf("⚠️ 跳过 %s 列: %s.%s", field.DefaultKind, table.Name, field.Name)
+			continue
+		}
+
+		column := model.Column{
+			ColumnName:      field.Name,
+			Comment:         field.Comment,
+			Type:            field.Type,
+			IsNullable:      isClickhouseNullable(field.Type),
+			IsIndexed:       field.IsInSortingKey == 1,
+			OrdinalPosition: int(field.Position),
+		}
+		if field.DefaultKind == "DEFAULT" && field.DefaultExpression != "" {
+			defaultExpression := field.DefaultExpression
+			column.Default = &defaultExpression
+		}
+		schema.Columns = append(schema.Columns, column)
+		columnIndexMap[column.ColumnName] = len(schema.Columns) - 1
+	}
+
+	// 排序键按 ORDER BY 中的顺序组成索引，toDate(ts) 这类表达式无法对应到列，直接跳过
+	sortingKey := model.Index{IndexName: clickhouseSortingKeyIndexName}
+	for _, expression := range splitClickhouseExpressions(table.SortingKey) {
+		colIdx, exists := columnIndexMap[strings.Trim(expression, "`")]
+		if !exists {
+			continue
+		}
+		sortingKey.Columns = append(sortingKey.Columns, schema.Columns[colIdx])
+		sortingKey.Parts = append(sortingKey.Parts, model.IndexPart{
+			ColumnName: schema.Columns[colIdx].ColumnName,
+			Seq:        len(sortingKey.Columns),
+			Direction:  "ASC",
+		})
+	}
+	if len(sortingKey.Columns) > 0 {
+		schema.Indexes = append(schema.Indexes, sortingKey)
+	}
+
+	return schema
+}
+
+// FilterTables 根据配置文件过滤表，规则见 filterTables
+func (p *ClickhouseParser) FilterTables(schemas []model.Schema) (filtered []model.Schema) {
+	return filterTables(p.configger.GenerateConfig, schemas)
+}
+
+// isClickhouseNullable 判断列类型是否可空
+// 可空类型形如 Nullable(String)，也可能被 LowCardinality 包裹：LowCardinality(Nullable(String))
+func isClickhouseNullable(columnType string) bool {
+	columnType = strings.TrimPrefix(columnType, "LowCardinality(")
+	return strings.HasPrefix(columnType, "Nullable(")
+}
+
+// splitClickhouseExpressions 按顶层逗号拆分排序键、分区键等表达式列表
+// 示例: "event_date, intHash32(user_id), id" -> ["event_date", "intHash32(user_id)", "id"]
+func splitClickhouseExpressions(expressions string) (parts []string) {
+	depth, start := 0, 0
+	for i, ch := range expressions {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(expressions[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(expressions[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitClickhouseExpressions(t *testing.T) {
+	cases := map[string][]string{
+		"":                                      nil,
+		"id":                                    {"id"},
+		"event_date, user_id":                   {"event_date", "user_id"},
+		"toDate(ts), intHash32(user_id), id":    {"toDate(ts)", "intHash32(user_id)", "id"},
+		"tuple(a, b), `weird col`":              {"tuple(a, b)", "`weird col`"},
+		"toStartOfHour(ts, 'Asia/Shanghai'), k": {"toStartOfHour(ts, 'Asia/Shanghai')", "k"},
+	}
+	for expressions, expected := range cases {
+		if actual := splitClickhouseExpressions(expressions); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("splitClickhouseExpressions(%q) = %q，期望 %q", expressions, actual, expected)
+		}
+	}
+}
+
+func TestBuildClickhouseSchema(t *testing.T) {
+	table := clickhouseTable{
+		Name:         "t_event",
+		Engine:       "MergeTree",
+		SortingKey:   "toDate(event_time), user_id, event_time",
+		PartitionKey: "toYYYYMM(event_time)",
+		Comment:      "事件表",
+	}
+	fields := []clickhouseColumn{
+		{Table: "t_event", Name: "event_time", Type: "DateTime64(3)", IsInSortingKey: 1},
+		{Table: "t_event", Name: "user_id", Type: "UInt64", IsInSortingKey: 1},
+		{Table: "t_event", Name: "country", Type: "LowCardinality(Nullable(String))"},
+		{Table: "t_event", Name: "amount", Type: "Nullable(Decimal(18, 4))", DefaultKind: "DEFAULT", DefaultExpression: "0"},
+		{Table: "t_event", Name: "event_date", Type: "Date", DefaultKind: "MATERIALIZED", DefaultExpression: "toDate(event_time)"},
+	}
+
+	schema := buildClickhouseSchema(table, fields)
+
+	if schema.Engine != "MergeTree" || schema.PartitionKey != "toYYYYMM(event_time)" || schema.Comment != "事件表" {
+		t.Errorf("表信息解析不正确: %s", schema.Json())
+	}
+	if len(schema.Columns) != 4 {
+		t.Fatalf("MATERIALIZED 列应被跳过，期望 4 列，实际为 %d", len(schema.Columns))
+	}
+	if schema.Columns[0].IsNullable || !schema.Columns[0].IsIndexed {
+		t.Errorf("event_time 应为非空的排序键列: %+v", schema.Columns[0])
+	}
+	if !schema.Columns[2].IsNullable {
+		t.Errorf("LowCardinality(Nullable(...)) 应为可空列: %+v", schema.Columns[2])
+	}
+	if schema.Columns[3].Default == nil || *schema.Columns[3].Default != "0" || !schema.Columns[3].IsNullable {
+		t.Errorf("amount 列默认值或可空性解析不正确: %+v", schema.Columns[3])
+	}
+	if schema.PrimaryKey.Columns != nil || schema.UniqueIndex != nil {
+		t.Errorf("ClickHouse 表不应有主键和唯一索引: %s", schema.Json())
+	}
+	if len(schema.Indexes) != 1 || schema.Indexes[0].IndexName != clickhouseSortingKeyIndexName {
+		t.Fatalf("排序键解析不正确: %+v", schema.Indexes)
+	}
+	sortingKeyColumns := schema.Indexes[0].Columns
+	if len(sortingKeyColumns) != 2 || sortingKeyColumns[0].ColumnName != "user_id" || sortingKeyColumns[1].ColumnName != "event_time" {
+		t.Errorf("排序键应按 ORDER BY 顺序包含 user_id、event_time: %+v", sortingKeyColumns)
+	}
+}
@@ -2,7 +2,8 @@ package parser
 
 import (
 	"fmt"
-
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/LingoJack/model_infrax/config"
@@ -21,17 +22,14 @@ type DatabaseParser struct {
 }
 
 func NewDatabaseParser(cfg *config.Configger) (*DatabaseParser, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.GenerateConfig.Username,
-		cfg.GenerateConfig.Password,
-		cfg.GenerateConfig.Host,
-		cfg.GenerateConfig.Port,
-		cfg.GenerateConfig.DatabaseName,
-	)
+	dsn, err := mysqlDSN(cfg.GenerateConfig)
+	if err != nil {
+		return nil, fmt.Errorf("构建连接串失败: %w", err)
+	}
 
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	if err != nil {
-		return nil, fmt.Errorf("连接数据库失败: %w", err)
+		return nil, fmt.Errorf("连接数据库失败: %w", redactPassword(err, cfg.GenerateConfig))
 	}
 
 	return &DatabaseParser{
@@ -40,205 +38,326 @@ func NewDatabaseParser(cfg *config.Configger) (*DatabaseParser, error) {
 	}, nil
 }
 
+// mysqlTable information_schema.TABLES 中的表信息
 type mysqlTable struct {
-	Name          string  `json:"ColumnName"`      // 表名
-	Engine        string  `json:"Engine"`          // 存储引擎
-	Version       int     `json:"Version"`         // 版本号
-	RowFormat     string  `json:"Row_format"`      // 行格式
-	Rows          int64   `json:"Rows"`            // 行数（估算值）
-	AvgRowLength  int64   `json:"Avg_row_length"`  // 平均行长度
-	DataLength    int64   `json:"Data_length"`     // 数据长度
-	MaxDataLength int64   `json:"Max_data_length"` // 最大数据长度
-	IndexLength   int64   `json:"Index_length"`    // 索引长度
-	DataFree      int64   `json:"Data_free"`       // 空闲空间
-	AutoIncrement *int64  `json:"Auto_increment"`  // 自增值（可能为null）
-	CreateTime    *string `json:"Create_time"`     // 创建时间（可能为null）
-	UpdateTime    *string `json:"Update_time"`     // 更新时间（可能为null）
-	CheckTime     *string `json:"Check_time"`      // 检查时间（可能为null）
-	Collation     string  `json:"Collation"`       // 字符集校对规则
-	Checksum      *int64  `json:"Checksum"`        // 校验和（可能为null）
-	CreateOptions string  `json:"Create_options"`  // 创建选项
-	Comment       string  `json:"Comment"`         // 表注释
+	TableName    string `gorm:"column:table_name"`    // 表名
+	TableComment string `gorm:"column:table_comment"` // 表注释
 }
 
-type mysqlField struct {
-	Field      string  `json:"Field"`      // 字段名
-	Type       string  `json:"Type"`       // 字段类型
-	Collation  *string `json:"Collation"`  // 字符集校对规则（可能为null）
-	Null       string  `json:"Null"`       // 是否允许为NULL（YES/NO）
-	Key        string  `json:"Key"`        // 键类型（PRI/UNI/MUL等）
-	Default    *string `json:"Default"`    // 默认值（可能为null）
-	Extra      string  `json:"Extra"`      // 额外信息（如auto_increment）
-	Privileges string  `json:"Privileges"` // 权限信息
-	Comment    string  `json:"Comment"`    // 字段注释
+// mysqlColumn information_schema.COLUMNS 中的列信息
+type mysqlColumn struct {
+	TableName              string  `gorm:"column:table_name"`               // 表名
+	ColumnName             string  `gorm:"column:column_name"`              // 列名
+	OrdinalPosition        int     `gorm:"column:ordinal_position"`         // 列在表中的位置（从1开始）
+	ColumnDefault          *string `gorm:"column:column_default"`           // 默认值（可能为null）
+	IsNullable             string  `gorm:"column:is_nullable"`              // 是否允许为NULL（YES/NO）
+	ColumnType             string  `gorm:"column:column_type"`              // 完整的列类型（如 bigint unsigned、varchar(128)）
+	CharacterMaximumLength *int64  `gorm:"column:character_maximum_length"` // 字符类型的最大长度（可能为null）
+	NumericPrecision       *int64  `gorm:"column:numeric_precision"`        // 数值类型的精度（可能为null）
+	NumericScale           *int64  `gorm:"column:numeric_scale"`            // 数值类型的小数位数（可能为null）
+	CollationName          *string `gorm:"column:collation_name"`           // 字符集校对规则（可能为null）
+	Extra                  string  `gorm:"column:extra"`                    // 额外信息（如 auto_increment、on update CURRENT_TIMESTAMP）
+	ColumnComment          string  `gorm:"column:column_comment"`           // 列注释
 }
 
-// mysqlIndex MySQL索引信息结构体
+// mysqlIndex information_schema.STATISTICS 中的索引信息，每个索引列一行
 type mysqlIndex struct {
-	Table        string  `json:"Table"`         // 表名
-	NonUnique    int     `json:"Non_unique"`    // 是否非唯一索引（0=唯一索引，1=非唯一索引）
-	KeyName      string  `json:"Key_name"`      // 索引名称
-	SeqInIndex   int     `json:"Seq_in_index"`  // 字段在索引中的序号（从1开始）
-	ColumnName   string  `json:"Column_name"`   // 列名
-	Collation    *string `json:"Collation"`     // 排序方式（A=升序，D=降序，NULL=未排序）
-	Cardinality  *int64  `json:"Cardinality"`   // 索引中唯一值的数量估算（可能为null）
-	SubPart      *int    `json:"Sub_part"`      // 索引前缀长度（可能为null）
-	Packed       *string `json:"Packed"`        // 关键字如何被压缩（可能为null）
-	Null         string  `json:"Null"`          // 列是否可以包含NULL值
-	IndexType    string  `json:"Index_type"`    // 索引类型（BTREE/HASH/FULLTEXT/SPATIAL）
-	Comment      string  `json:"Comment"`       // 索引注释
-	IndexComment string  `json:"Index_comment"` // 索引注释（创建索引时的COMMENT）
-	Visible      string  `json:"Visible"`       // 索引是否可见（YES/NO）
-	Expression   *string `json:"Expression"`    // 表达式索引的表达式（可能为null）
+	TableName  string  `gorm:"column:table_name"`   // 表名
+	IndexName  string  `gorm:"column:index_name"`   // 索引名称
+	NonUnique  int     `gorm:"column:non_unique"`   // 是否非唯一索引（0=唯一索引，1=非唯一索引）
+	SeqInIndex int     `gorm:"column:seq_in_index"` // 字段在索引中的序号（从1开始）
+	ColumnName *string `gorm:"column:column_name"`  // 列名（函数索引为null）
+	SubPart    *int64  `gorm:"column:sub_part"`     // 前缀索引的长度（索引整列时为null）
+	Collation  *string `gorm:"column:collation"`    // 排序方向（A=升序，D=降序，null=不排序）
+	IndexType  string  `gorm:"column:index_type"`   // 索引类型（BTREE、HASH、FULLTEXT、SPATIAL）
 }
 
-// Parse 获取所有表名
+// foreignKeyRow 外键信息，每个外键列一行，MySQL、PostgreSQL、SQLite 共用
+type foreignKeyRow struct {
+	TableName            string `gorm:"column:table_name"`             // 表名
+	ConstraintName       string `gorm:"column:constraint_name"`        // 外键约束名称
+	ColumnName           string `gorm:"column:column_name"`            // 外键列名
+	OrdinalPosition      int    `gorm:"column:ordinal_position"`       // 列在外键中的序号（从1开始）
+	ReferencedTableName  string `gorm:"column:referenced_table_name"`  // 被引用的表名
+	ReferencedColumnName string `gorm:"column:referenced_column_name"` // 被引用的列名
+	UpdateRule           string `gorm:"column:update_rule"`            // 更新时的动作
+	DeleteRule           string `gorm:"column:delete_rule"`            // 删除时的动作
+}
+
+// information_schema 在 MySQL 8 中返回大写列名，这里统一使用小写别名
+const mysqlTablesSQL = `
+select table_name as table_name,
+       table_comment as table_comment
+from information_schema.tables
+where table_schema = ?
+  and table_type = 'BASE TABLE'
+order by table_name`
+
+const mysqlColumnsSQL = `
+select table_name as table_name,
+       column_name as column_name,
+       ordinal_position as ordinal_position,
+       column_default as column_default,
+       is_nullable as is_nullable,
+       column_type as column_type,
+       character_maximum_length as character_maximum_length,
+       numeric_precision as numeric_precision,
+       numeric_scale as numeric_scale,
+       collation_name as collation_name,
+       extra as extra,
+       column_comment as column_comment
+from information_schema.columns
+where table_schema = ?
+order by table_name, ordinal_position`
+
+const mysqlIndexesSQL = `
+select table_name as table_name,
+       index_name as index_name,
+       non_unique as non_unique,
+       seq_in_index as seq_in_index,
+       column_name as column_name,
+       sub_part as sub_part,
+       collation as collation,
+       index_type as index_type
+from information_schema.statistics
+where table_schema = ?
+order by table_name, index_name, seq_in_index`
+
+const mysqlForeignKeysSQL = `
+select kcu.table_name as table_name,
+       kcu.constraint_name as constraint_name,
+       kcu.column_name as column_name,
+       kcu.ordinal_position as ordinal_position,
+       kcu.referenced_table_name as referenced_table_name,
+       kcu.referenced_column_name as referenced_column_name,
+       rc.update_rule as update_rule,
+       rc.delete_rule as delete_rule
+from information_schema.key_column_usage kcu
+join information_schema.referential_constraints rc
+  on rc.constraint_schema = kcu.constraint_schema
+ and rc.table_name = kcu.table_name
+ and rc.constraint_name = kcu.constraint_name
+where kcu.table_schema = ?
+  and kcu.referenced_table_name is not null
+order by kcu.table_name, kcu.constraint_name, kcu.ordinal_position`
+
+// mysqlOnUpdateRegexp 匹配 extra 中的 ON UPDATE 表达式，如 "on update CURRENT_TIMESTAMP(3)"
+var mysqlOnUpdateRegexp = regexp.MustCompile(`(?i)on update (\S+)`)
+
+// Parse 解析数据库下所有表的结构
+// 表、列、索引、外键分别通过一次 information_schema 批量查询获取，再按表名分组，避免逐表查询
 func (p *DatabaseParser) Parse() (schemas []model.Schema, err error) {
-	// 执行 sqlShowTableStatus 拿到所有返回值
+	databaseName := p.configger.GenerateConfig.DatabaseName
+
 	var tables []mysqlTable
-	if err = p.db.Raw("show table status").Scan(&tables).Error; err != nil {
+	if err = p.db.Raw(mysqlTablesSQL, databaseName).Scan(&tables).Error; err != nil {
 		return nil, fmt.Errorf("查询数据库表失败: %w", err)
 	}
 
-	// 使用 lo.Map 遍历所有表并构建 Schema
-	schemas = lo.Map(tables, func(table mysqlTable, index int) model.Schema {
-		tableName := table.Name
-		tableComment := table.Comment
+	var columns []mysqlColumn
+	if err = p.db.Raw(mysqlColumnsSQL, databaseName).Scan(&columns).Error; err != nil {
+		return nil, fmt.Errorf("查询表字段失败: %w", err)
+	}
 
-		// 查询表的所有字段信息
-		var fields []mysqlField
-		err = p.db.Raw(fmt.Sprintf("show full fields from `%s`", tableName)).Scan(&fields).Error
-		if err != nil {
-			panic(err)
-		}
+	var indexes []mysqlIndex
+	if err = p.db.Raw(mysqlIndexesSQL, databaseName).Scan(&indexes).Error; err != nil {
+		return nil, fmt.Errorf("查询表索引失败: %w", err)
+	}
 
-		// 构建列信息和列名到列的映射
-		var columns []model.Column
-		name2Column := make(map[string]model.Column) // 初始化 map
-		lo.ForEach(fields, func(field mysqlField, index int) {
-			column := model.Column{
-				ColumnName:      field.Field,
-				Collate:         tool.Stringify(field.Collation), // Stringify 已经处理了 nil 指针
-				Comment:         field.Comment,
-				Type:            field.Type,
-				Default:         field.Default, // 设置默认值
-				IsAutoIncrement: strings.Contains(field.Extra, "auto_increment"),
-				IsNullable:      field.Null == "YES",
-			}
-			columns = append(columns, column)
-			name2Column[column.ColumnName] = column
-		})
+	var foreignKeys []foreignKeyRow
+	if err = p.db.Raw(mysqlForeignKeysSQL, databaseName).Scan(&foreignKeys).Error; err != nil {
+		return nil, fmt.Errorf("查询表外键失败: %w", err)
+	}
 
-		// 查询表的所有索引信息
-		var mysqlIndexes []mysqlIndex
-		err = p.db.Raw(fmt.Sprintf("show index from `%s`", tableName)).Scan(&mysqlIndexes).Error
-		if err != nil {
-			panic(err)
-		}
+	table2Columns := lo.GroupBy(columns, func(column mysqlColumn) string {
+		return column.TableName
+	})
+	table2Indexes := lo.GroupBy(indexes, func(index mysqlIndex) string {
+		return index.TableName
+	})
+	table2ForeignKeys := lo.GroupBy(foreignKeys, func(foreignKey foreignKeyRow) string {
+		return foreignKey.TableName
+	})
 
-		// 构建索引名到列的映射
-		indexName2Columns := make(map[string][]model.Column)
-		lo.ForEach(mysqlIndexes, func(index mysqlIndex, i int) {
-			indexName2Columns[index.KeyName] = append(indexName2Columns[index.KeyName], name2Column[index.ColumnName])
-		})
+	schemas = lo.Map(tables, func(table mysqlTable, _ int) model.Schema {
+		return buildMysqlSchema(table, table2Columns[table.TableName], table2Indexes[table.TableName], table2ForeignKeys[table.TableName])
+	})
 
-		// 构建索引名到索引对象的映射，用于后续查找
-		inexName2Index := make(map[string]model.Index)
-
-		var primaryKey model.Index
-		var indexes []model.Index
-
-		// 将 map 转换为 Index 切片
-		indexes = lo.MapToSlice(indexName2Columns, func(key string, value []model.Column) model.Index {
-			idx := model.Index{
-				IndexName: key,
-				Columns:   value,
-			}
-			// 识别主键
-			if key == "PRIMARY" {
-				primaryKey = idx
-			}
-			inexName2Index[key] = idx
-			return idx
-		})
+	return applyAnnotations(schemas), nil
+}
 
-		// 提取唯一索引
-		var uniqueIndexes []model.Index
-		lo.ForEach(mysqlIndexes, func(index mysqlIndex, i int) {
-			if index.NonUnique == 0 {
-				uniqueIndexes = append(uniqueIndexes, inexName2Index[index.KeyName])
-			}
-		})
+// buildMysqlSchema 将 information_schema 中查询到的行转换为表结构
+// UniqueIndex 和 Indexes 按索引名排序
+func buildMysqlSchema(table mysqlTable, fields []mysqlColumn, mysqlIndexes []mysqlIndex, foreignKeyRows []foreignKeyRow) model.Schema {
+	tableName := table.TableName
+	tableComment := table.TableComment
 
-		// 找到所有索引的列
-		var indexedColumns []model.Column
-		lo.ForEach(indexes, func(index model.Index, i int) {
-			indexedColumns = append(indexedColumns, index.Columns...)
-		})
+	// 构建列信息和列名到列下标的映射
+	var columns []model.Column
+	columnIndexMap := make(map[string]int)
+	lo.ForEach(fields, func(field mysqlColumn, index int) {
+		column := model.Column{
+			ColumnName:         field.ColumnName,
+			Collate:            tool.Stringify(field.CollationName), // Stringify 已经处理了 nil 指针
+			Comment:            field.ColumnComment,
+			Type:               field.ColumnType,
+			Default:            field.ColumnDefault, // 设置默认值
+			IsAutoIncrement:    strings.Contains(field.Extra, "auto_increment"),
+			IsNullable:         field.IsNullable == "YES",
+			CharacterMaxLength: field.CharacterMaximumLength,
+			NumericPrecision:   field.NumericPrecision,
+			NumericScale:       field.NumericScale,
+			OrdinalPosition:    field.OrdinalPosition,
+		}
+		if matches := mysqlOnUpdateRegexp.FindStringSubmatch(field.Extra); matches != nil {
+			column.OnUpdate = &matches[1]
+		}
+		column.EnumValues = parseEnumValues(field.ColumnType)
+		columns = append(columns, column)
+		columnIndexMap[column.ColumnName] = len(columns) - 1
+	})
 
-		// 找到所有唯一索引的列
-		var uniqueIndexedColumns []model.Column
-		lo.ForEach(uniqueIndexes, func(index model.Index, i int) {
-			uniqueIndexedColumns = append(uniqueIndexedColumns, index.Columns...)
-		})
+	// 函数索引的表达式部分没有对应的列，只保留其余的列会得到语义不同的索引，整个索引直接跳过
+	expressionIndexNames := lo.FilterMap(mysqlIndexes, func(index mysqlIndex, _ int) (string, bool) {
+		_, exists := columnIndexMap[tool.Stringify(index.ColumnName)]
+		return index.IndexName, index.ColumnName == nil || !exists
+	})
+	mysqlIndexes = lo.Filter(mysqlIndexes, func(index mysqlIndex, _ int) bool {
+		return !lo.Contains(expressionIndexNames, index.IndexName)
+	})
+
+	// 按 主键优先、索引名、列序号 排序，保证多次生成的方法顺序一致
+	sort.SliceStable(mysqlIndexes, func(i, j int) bool {
+		a, b := mysqlIndexes[i], mysqlIndexes[j]
+		if (a.IndexName == "PRIMARY") != (b.IndexName == "PRIMARY") {
+			return a.IndexName == "PRIMARY"
+		}
+		if a.IndexName != b.IndexName {
+			return a.IndexName < b.IndexName
+		}
+		return a.SeqInIndex < b.SeqInIndex
+	})
 
-		// 找到所有主键的列
-		var primaryKeyColumns []model.Column
-		lo.ForEach(primaryKey.Columns, func(column model.Column, i int) {
-			primaryKeyColumns = append(primaryKeyColumns, column)
+	// 标记索引列：直接在 columns 切片中更新
+	for _, index := range mysqlIndexes {
+		colIdx := columnIndexMap[*index.ColumnName]
+		columns[colIdx].IsIndexed = true
+		if index.NonUnique == 0 {
+			columns[colIdx].IsUnique = true
+		}
+		if index.IndexName == "PRIMARY" {
+			columns[colIdx].IsPrimaryKey = true
+		}
+	}
+
+	// 每个索引一行转换为 Index，行已排序，相邻的同名行属于同一个索引
+	var indexes []model.Index
+	for _, row := range mysqlIndexes {
+		if len(indexes) == 0 || indexes[len(indexes)-1].IndexName != row.IndexName {
+			indexes = append(indexes, model.Index{
+				IndexName: row.IndexName,
+				IsUnique:  row.NonUnique == 0,
+				IndexType: row.IndexType,
+			})
+		}
+		index := &indexes[len(indexes)-1]
+		index.Columns = append(index.Columns, columns[columnIndexMap[*row.ColumnName]])
+		index.Parts = append(index.Parts, model.IndexPart{
+			ColumnName: *row.ColumnName,
+			Seq:        row.SeqInIndex,
+			SubPart:    row.SubPart,
+			Direction:  lo.Ternary(tool.Stringify(row.Collation) == "D", "DESC", "ASC"),
 		})
+	}
 
-		// 标记索引列：直接在 columns 切片中更新
-		for i := range columns {
-			columnName := columns[i].ColumnName
-
-			// 检查是否为索引列
-			for _, indexedCol := range indexedColumns {
-				if indexedCol.ColumnName == columnName {
-					columns[i].IsIndexed = true
-					break
-				}
-			}
-
-			// 检查是否为唯一索引列
-			for _, uniqueCol := range uniqueIndexedColumns {
-				if uniqueCol.ColumnName == columnName {
-					columns[i].IsUnique = true
-					break
-				}
-			}
-
-			// 检查是否为主键列
-			for _, pkCol := range primaryKeyColumns {
-				if pkCol.ColumnName == columnName {
-					columns[i].IsPrimaryKey = true
-					break
-				}
-			}
+	// 区分主键、唯一索引和普通索引，每个索引只出现在其中一处
+	var primaryKey model.Index
+	var uniqueIndexes, normalIndexes []model.Index
+	for _, index := range indexes {
+		switch {
+		case index.IndexName == "PRIMARY":
+			primaryKey = index
+		case index.IsUnique:
+			uniqueIndexes = append(uniqueIndexes, index)
+		default:
+			normalIndexes = append(normalIndexes, index)
 		}
+	}
+
+	// 构建 Schema 对象
+	return model.Schema{
+		Name:        tableName,
+		Comment:     tableComment,
+		Columns:     columns,
+		PrimaryKey:  primaryKey,
+		Indexes:     normalIndexes,
+		UniqueIndex: uniqueIndexes,
+		ForeignKeys: buildForeignKeys(foreignKeyRows),
+	}
+}
 
-		// 构建 Schema 对象
-		return model.Schema{
-			Name:        tableName,
-			Comment:     tableComment,
-			Columns:     columns,
-			PrimaryKey:  primaryKey,
-			Indexes:     indexes,
-			UniqueIndex: uniqueIndexes,
+// buildForeignKeys 按 约束名、列序号 排序后，将相邻的同名行合并为一个外键
+func buildForeignKeys(rows []foreignKeyRow) []model.ForeignKey {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.ConstraintName != b.ConstraintName {
+			return a.ConstraintName < b.ConstraintName
 		}
+		return a.OrdinalPosition < b.OrdinalPosition
 	})
 
-	return
+	var foreignKeys []model.ForeignKey
+	for _, row := range rows {
+		if len(foreignKeys) == 0 || foreignKeys[len(foreignKeys)-1].Name != row.ConstraintName {
+			foreignKeys = append(foreignKeys, model.ForeignKey{
+				Name:            row.ConstraintName,
+				ReferencedTable: row.ReferencedTableName,
+				OnDelete:        row.DeleteRule,
+				OnUpdate:        row.UpdateRule,
+			})
+		}
+		foreignKey := &foreignKeys[len(foreignKeys)-1]
+		foreignKey.Columns = append(foreignKey.Columns, row.ColumnName)
+		foreignKey.ReferencedColumns = append(foreignKey.ReferencedColumns, row.ReferencedColumnName)
+	}
+	return foreignKeys
 }
 
-// FilterTables 根据配置文件过滤表
-func (p *DatabaseParser) FilterTables(schemas []model.Schema) (filtered []model.Schema) {
-	if p.configger.GenerateConfig.AllTables {
-		filtered = schemas
-		return
+// parseEnumValues 从 information_schema 的 column_type 中解析 ENUM、SET 列允许的取值，其他类型返回 nil
+// 取值中的单引号以两个连续的单引号表示，解析时还原为一个
+// 示例:
+//   - enum('draft','published') -> [draft published]
+//   - set('read','write') -> [read write]
+func parseEnumValues(columnType string) []string {
+	lowerType := strings.ToLower(columnType)
+	if !strings.HasPrefix(lowerType, "enum(") && !strings.HasPrefix(lowerType, "set(") {
+		return nil
 	}
-	filtered = lo.Filter(schemas, func(schema model.Schema, index int) bool {
-		return lo.Contains(p.configger.GenerateConfig.TableNames, schema.Name)
-	})
-	return
+
+	var values []string
+	var value strings.Builder
+	inQuote := false
+	body := columnType[strings.Index(columnType, "(")+1 : strings.LastIndex(columnType, ")")]
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		switch {
+		case !inQuote && ch == '\'':
+			inQuote = true
+		case inQuote && ch == '\'' && i+1 < len(body) && body[i+1] == '\'':
+			value.WriteByte('\'')
+			i++
+		case inQuote && ch == '\'':
+			inQuote = false
+			values = append(values, value.String())
+			value.Reset()
+		case inQuote:
+			value.WriteByte(ch)
+		}
+	}
+	return values
+}
+
+// FilterTables 根据配置文件过滤表，规则见 filterTables
+func (p *DatabaseParser) FilterTables(schemas []model.Schema) (filtered []model.Schema) {
+	return filterTables(p.configger.GenerateConfig, schemas)
 }
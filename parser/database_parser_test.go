@@ -4,7 +4,9 @@ import (
 	"fmt"
 
 	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/model"
 	"github.com/LingoJack/model_infrax/tool"
+	"github.com/samber/lo"
 
 	"os"
 	"testing"
@@ -45,7 +47,7 @@ func TestSql1(t *testing.T) {
 		panic(err)
 	}
 	var tables []mysqlTable
-	err = parser.db.Raw("show table status").Scan(&tables).Error
+	err = parser.db.Raw(mysqlTablesSQL, configger.GenerateConfig.DatabaseName).Scan(&tables).Error
 	if err != nil {
 		panic(err)
 	}
@@ -106,3 +108,129 @@ func TestSql4(t *testing.T) {
 
 	fmt.Println(tool.JsonifyIndent(table))
 }
+
+func TestBuildMysqlSchema(t *testing.T) {
+	varcharLength, precision, scale := int64(64), int64(10), int64(2)
+	onUpdateExtra := "DEFAULT_GENERATED on update CURRENT_TIMESTAMP(3)"
+	fields := []mysqlColumn{
+		{TableName: "t_order", ColumnName: "id", OrdinalPosition: 1, IsNullable: "NO", ColumnType: "bigint unsigned", Extra: "auto_increment"},
+		{TableName: "t_order", ColumnName: "order_no", OrdinalPosition: 2, IsNullable: "NO", ColumnType: "varchar(64)", CharacterMaximumLength: &varcharLength},
+		{TableName: "t_order", ColumnName: "amount", OrdinalPosition: 3, IsNullable: "YES", ColumnType: "decimal(10,2)", NumericPrecision: &precision, NumericScale: &scale},
+		{TableName: "t_order", ColumnName: "update_time", OrdinalPosition: 4, IsNullable: "NO", ColumnType: "datetime(3)", Extra: onUpdateExtra},
+	}
+	indexes := []mysqlIndex{
+		{TableName: "t_order", IndexName: "PRIMARY", SeqInIndex: 1, ColumnName: lo.ToPtr("id")},
+		{TableName: "t_order", IndexName: "uk_order_no", SeqInIndex: 1, ColumnName: lo.ToPtr("order_no")},
+		{TableName: "t_order", IndexName: "idx_func", NonUnique: 1, SeqInIndex: 1},
+		{TableName: "t_order", IndexName: "idx_amount_func", NonUnique: 1, SeqInIndex: 1, ColumnName: lo.ToPtr("amount")},
+		{TableName: "t_order", IndexName: "idx_amount_func", NonUnique: 1, SeqInIndex: 2},
+	}
+
+	schema := buildMysqlSchema(mysqlTable{TableName: "t_order", TableComment: "订单表"}, fields, indexes, nil)
+
+	if schema.Name != "t_order" || schema.Comment != "订单表" || len(schema.Columns) != 4 {
+		t.Fatalf("表信息解析不正确: %s", schema.Json())
+	}
+	if !schema.Columns[0].IsAutoIncrement || !schema.Columns[0].IsPrimaryKey || schema.Columns[0].OrdinalPosition != 1 {
+		t.Errorf("id 列应为自增主键: %+v", schema.Columns[0])
+	}
+	if schema.Columns[1].CharacterMaxLength == nil || *schema.Columns[1].CharacterMaxLength != 64 || !schema.Columns[1].IsUnique {
+		t.Errorf("order_no 列元数据解析不正确: %+v", schema.Columns[1])
+	}
+	if *schema.Columns[2].NumericPrecision != 10 || *schema.Columns[2].NumericScale != 2 || !schema.Columns[2].IsNullable {
+		t.Errorf("amount 列元数据解析不正确: %+v", schema.Columns[2])
+	}
+	if schema.Columns[3].OnUpdate == nil || *schema.Columns[3].OnUpdate != "CURRENT_TIMESTAMP(3)" {
+		t.Errorf("update_time 列的 ON UPDATE 表达式解析不正确: %+v", schema.Columns[3])
+	}
+	if schema.PrimaryKey.IndexName != "PRIMARY" || len(schema.UniqueIndex) != 1 || len(schema.Indexes) != 0 {
+		t.Errorf("函数索引应被跳过，主键和唯一索引不应出现在 Indexes 中: %+v", schema.Indexes)
+	}
+	if schema.Columns[2].IsIndexed {
+		t.Errorf("只出现在函数索引中的列不应标记为索引列: %+v", schema.Columns[2])
+	}
+}
+
+func TestParseEnumValues(t *testing.T) {
+	for columnType, expected := range map[string]string{
+		"enum('draft','published')":   "[draft published]",
+		"set('read','write')":         "[read write]",
+		"enum('a,b','it''s','')":      "[a,b it's ]",
+		"ENUM('Y','N') CHARACTER SET": "[Y N]",
+		"varchar(64)":                 "[]",
+	} {
+		if values := parseEnumValues(columnType); fmt.Sprint(values) != expected {
+			t.Errorf("枚举取值解析不正确 [%s]: %q, 期望: %s", columnType, values, expected)
+		}
+	}
+}
+
+func TestBuildMysqlSchemaIndexOrder(t *testing.T) {
+	fields := []mysqlColumn{
+		{TableName: "t_user", ColumnName: "id", OrdinalPosition: 1, ColumnType: "bigint"},
+		{TableName: "t_user", ColumnName: "tenant_id", OrdinalPosition: 2, ColumnType: "bigint"},
+		{TableName: "t_user", ColumnName: "name", OrdinalPosition: 3, ColumnType: "varchar(64)"},
+		{TableName: "t_user", ColumnName: "create_time", OrdinalPosition: 4, ColumnType: "datetime"},
+	}
+	prefixLength := int64(16)
+	// 行的顺序模拟 information_schema 按大小写不敏感排序后的结果
+	indexes := []mysqlIndex{
+		{IndexName: "idx_name", NonUnique: 1, SeqInIndex: 1, ColumnName: lo.ToPtr("name"), SubPart: &prefixLength, Collation: lo.ToPtr("A"), IndexType: "BTREE"},
+		{IndexName: "uk_tenant_name", SeqInIndex: 2, ColumnName: lo.ToPtr("name"), Collation: lo.ToPtr("A"), IndexType: "BTREE"},
+		{IndexName: "idx_create_time", NonUnique: 1, SeqInIndex: 1, ColumnName: lo.ToPtr("create_time"), Collation: lo.ToPtr("D"), IndexType: "BTREE"},
+		{IndexName: "PRIMARY", SeqInIndex: 1, ColumnName: lo.ToPtr("id"), Collation: lo.ToPtr("A"), IndexType: "BTREE"},
+		{IndexName: "uk_tenant_name", SeqInIndex: 1, ColumnName: lo.ToPtr("tenant_id"), Collation: lo.ToPtr("A"), IndexType: "BTREE"},
+	}
+
+	for i := 0; i < 10; i++ {
+		schema := buildMysqlSchema(mysqlTable{TableName: "t_user"}, fields, lo.Shuffle(append([]mysqlIndex(nil), indexes...)), nil)
+
+		indexNames := lo.Map(schema.Indexes, func(index model.Index, _ int) string {
+			return index.IndexName
+		})
+		if fmt.Sprint(indexNames) != "[idx_create_time idx_name]" {
+			t.Fatalf("索引顺序不稳定，主键和唯一索引不应出现在 Indexes 中: %v", indexNames)
+		}
+		if schema.PrimaryKey.IndexName != "PRIMARY" || len(schema.UniqueIndex) != 1 || schema.UniqueIndex[0].IndexName != "uk_tenant_name" {
+			t.Fatalf("联合唯一索引不应重复: %s", tool.JsonifyIndent(schema.UniqueIndex))
+		}
+
+		uniqueIndex := schema.UniqueIndex[0]
+		if uniqueIndex.Columns[0].ColumnName != "tenant_id" || uniqueIndex.Parts[1].Seq != 2 || !uniqueIndex.IsUnique {
+			t.Errorf("联合唯一索引的列顺序不正确: %s", tool.JsonifyIndent(uniqueIndex))
+		}
+		if part := schema.Indexes[1].Parts[0]; part.SubPart == nil || *part.SubPart != 16 || schema.Indexes[1].IndexType != "BTREE" {
+			t.Errorf("前缀索引元数据不正确: %+v", part)
+		}
+		if part := schema.Indexes[0].Parts[0]; part.Direction != "DESC" {
+			t.Errorf("降序索引元数据不正确: %+v", part)
+		}
+	}
+}
+
+func TestBuildMysqlSchemaForeignKeys(t *testing.T) {
+	fields := []mysqlColumn{
+		{TableName: "t_order_item", ColumnName: "id", OrdinalPosition: 1, ColumnType: "bigint"},
+		{TableName: "t_order_item", ColumnName: "tenant_id", OrdinalPosition: 2, ColumnType: "bigint"},
+		{TableName: "t_order_item", ColumnName: "order_no", OrdinalPosition: 3, ColumnType: "varchar(64)"},
+		{TableName: "t_order_item", ColumnName: "user_id", OrdinalPosition: 4, ColumnType: "bigint"},
+	}
+	foreignKeys := []foreignKeyRow{
+		{ConstraintName: "fk_order", ColumnName: "order_no", OrdinalPosition: 2, ReferencedTableName: "t_order", ReferencedColumnName: "order_no", UpdateRule: "CASCADE", DeleteRule: "CASCADE"},
+		{ConstraintName: "fk_user", ColumnName: "user_id", OrdinalPosition: 1, ReferencedTableName: "t_user", ReferencedColumnName: "id", UpdateRule: "RESTRICT", DeleteRule: "SET NULL"},
+		{ConstraintName: "fk_order", ColumnName: "tenant_id", OrdinalPosition: 1, ReferencedTableName: "t_order", ReferencedColumnName: "tenant_id", UpdateRule: "CASCADE", DeleteRule: "CASCADE"},
+	}
+
+	schema := buildMysqlSchema(mysqlTable{TableName: "t_order_item"}, fields, nil, foreignKeys)
+
+	if len(schema.ForeignKeys) != 2 {
+		t.Fatalf("外键数量不正确: %s", tool.JsonifyIndent(schema.ForeignKeys))
+	}
+	order, user := schema.ForeignKeys[0], schema.ForeignKeys[1]
+	if order.Name != "fk_order" || fmt.Sprint(order.Columns) != "[tenant_id order_no]" || fmt.Sprint(order.ReferencedColumns) != "[tenant_id order_no]" {
+		t.Errorf("联合外键的列顺序不正确: %+v", order)
+	}
+	if user.ReferencedTable != "t_user" || user.OnDelete != "SET NULL" || user.OnUpdate != "RESTRICT" {
+		t.Errorf("外键引用信息不正确: %+v", user)
+	}
+}
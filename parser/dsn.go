@@ -0,0 +1,323 @@
+package parser
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/LingoJack/model_infrax/config"
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+// tlsConfigName 注册到 mysql / clickhouse 驱动中的自定义 TLS 配置名称
+const tlsConfigName = "model_infrax"
+
+// mysqlDSN 构建 MySQL 连接串
+// 优先级: dsn > url_template > 根据 host、port、socket、tls 等配置构建
+func mysqlDSN(cfg config.GenerateConfig) (string, error) {
+	if cfg.DSN != "" {
+		return cfg.DSN, nil
+	}
+
+	password, err := cfg.ResolvePassword()
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.URLTemplate != "" {
+		// go-sql-driver 的连接串不带 scheme，兼容旧版 "mysql://..." 写法的模板
+		// 驱动按最后一个 @ 拆分用户信息，也不会对密码解码，因此去掉 scheme 后原样填充
+		cfg.URLTemplate = strings.TrimPrefix(cfg.URLTemplate, "mysql://")
+		return fillURLTemplate(cfg, password)
+	}
+
+	mysqlConfig := mysqldriver.NewConfig()
+	mysqlConfig.User = cfg.Username
+	mysqlConfig.Passwd = password
+	mysqlConfig.DBName = cfg.DatabaseName
+	mysqlConfig.Net = "tcp"
+	mysqlConfig.Addr = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	if cfg.Socket != "" {
+		mysqlConfig.Net = "unix"
+		mysqlConfig.Addr = cfg.Socket
+	}
+	mysqlConfig.Params = map[string]string{"charset": "utf8mb4"}
+	mysqlConfig.ParseTime = true
+	mysqlConfig.Loc = time.Local
+	mysqlConfig.Timeout = cfg.ConnectTimeout
+
+	if cfg.TLS.TLSEnabled() {
+		var tlsConfig *tls.Config
+		if tlsConfig, err = buildTLSConfig(cfg.TLS, cfg.Host); err != nil {
+			return "", err
+		}
+		if err = mysqldriver.RegisterTLSConfig(tlsConfigName, tlsConfig); err != nil {
+			return "", fmt.Errorf("注册 TLS 配置失败: %w", err)
+		}
+		mysqlConfig.TLSConfig = tlsConfigName
+	}
+
+	return mysqlConfig.FormatDSN(), nil
+}
+
+// postgresDSN 构建 PostgreSQL 连接串（libpq 的 key=value 格式）
+// 优先级: dsn > url_template > 根据 host、port、socket、tls 等配置构建
+func postgresDSN(cfg config.GenerateConfig) (string, error) {
+	if cfg.DSN != "" {
+		return cfg.DSN, nil
+	}
+
+	password, err := cfg.ResolvePassword()
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.URLTemplate != "" {
+		return fillURLTemplate(cfg, password)
+	}
+
+	// postgres 通过 socket 所在目录连接，host 直接填写目录即可
+	host := cfg.Host
+	if cfg.Socket != "" {
+		host = cfg.Socket
+	}
+
+	// TLS 模式的取值与 libpq 的 sslmode 一致，直接透传
+	sslMode := "disable"
+	if cfg.TLS.TLSEnabled() {
+		sslMode = cfg.TLS.Mode
+	}
+
+	params := [][2]string{
+		{"host", host},
+		{"user", cfg.Username},
+		{"password", password},
+		{"dbname", cfg.DatabaseName},
+		{"sslmode", sslMode},
+		{"TimeZone", "Local"},
+	}
+	if cfg.Port != 0 {
+		params = append(params, [2]string{"port", strconv.Itoa(cfg.Port)})
+	}
+	if cfg.ConnectTimeout > 0 {
+		// connect_timeout 以秒为单位，不足一秒按一秒处理
+		params = append(params, [2]string{"connect_timeout", strconv.Itoa(int(math.Ceil(cfg.ConnectTimeout.Seconds())))})
+	}
+	for _, param := range [][2]string{
+		{"sslrootcert", cfg.TLS.CAFile},
+		{"sslcert", cfg.TLS.CertFile},
+		{"sslkey", cfg.TLS.KeyFile},
+	} {
+		if cfg.TLS.TLSEnabled() && param[1] != "" {
+			params = append(params, param)
+		}
+	}
+
+	pairs := make([]string, 0, len(params))
+	for _, param := range params {
+		pairs = append(pairs, param[0]+"="+quotePostgresValue(param[1]))
+	}
+	return strings.Join(pairs, " "), nil
+}
+
+// clickhouseDSN 构建 ClickHouse 连接串（native 协议）
+// 优先级: dsn > url_template > 根据 host、port、tls 等配置构建
+func clickhouseDSN(cfg config.GenerateConfig) (string, error) {
+	if cfg.DSN != "" {
+		return cfg.DSN, nil
+	}
+
+	password, err := cfg.ResolvePassword()
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.URLTemplate != "" {
+		return fillURLTemplate(cfg, password)
+	}
+
+	query := url.Values{}
+	query.Set("database", cfg.DatabaseName)
+	query.Set("username", cfg.Username)
+	query.Set("password", password)
+	query.Set("read_timeout", "10")
+	query.Set("write_timeout", "20")
+	if cfg.ConnectTimeout > 0 {
+		query.Set("timeout", strconv.FormatFloat(cfg.ConnectTimeout.Seconds(), 'f', -1, 64))
+	}
+	if cfg.TLS.TLSEnabled() {
+		var tlsConfig *tls.Config
+		if tlsConfig, err = buildTLSConfig(cfg.TLS, cfg.Host); err != nil {
+			return "", err
+		}
+		if err = clickhouse.RegisterTLSConfig(tlsConfigName, tlsConfig); err != nil {
+			return "", fmt.Errorf("注册 TLS 配置失败: %w", err)
+		}
+		query.Set("secure", "true")
+		query.Set("tls_config", tlsConfigName)
+	}
+
+	return fmt.Sprintf("tcp://%s?%s", net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)), query.Encode()), nil
+}
+
+// redactedPassword 错误信息中代替密码显示的内容
+const redactedPassword = "xxxxx"
+
+// urlTemplateVerb 匹配 url_template 中的 fmt 占位符
+var urlTemplateVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// fillURLTemplate 按 用户名、密码、主机、端口、数据库名 的顺序填充 url_template
+// URL 形式的模板（包含 ://）按占位符所在的位置转义填充的值，并校验填充结果是合法的 URL，错误信息中不包含密码
+// 其他形式的模板（如 libpq 的 key=value）原样填充
+func fillURLTemplate(cfg config.GenerateConfig, password string) (string, error) {
+	args := []any{cfg.Username, password, cfg.Host, cfg.Port, cfg.DatabaseName}
+	authorityStart := strings.Index(cfg.URLTemplate, "://")
+	if authorityStart < 0 {
+		return fmt.Sprintf(cfg.URLTemplate, args...), nil
+	}
+	authorityStart += len("://")
+
+	fill := func(password string) string {
+		escapedArgs := append([]any{}, args...)
+		escapedArgs[1] = password
+		i := 0
+		for _, loc := range urlTemplateVerb.FindAllStringIndex(cfg.URLTemplate, -1) {
+			if cfg.URLTemplate[loc[0]:loc[1]] == "%%" {
+				continue
+			}
+			if i >= len(escapedArgs) {
+				break
+			}
+			if value, ok := escapedArgs[i].(string); ok {
+				escapedArgs[i] = escapeURLTemplateValue(cfg.URLTemplate, authorityStart, loc[0], value)
+			}
+			i++
+		}
+		return fmt.Sprintf(cfg.URLTemplate, escapedArgs...)
+	}
+
+	dsn := fill(password)
+	if _, err := url.Parse(dsn); err != nil {
+		// url.Error 的信息中包含完整的 URL，只保留原因，URL 中的密码替换为 xxxxx
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("url_template 填充后不是合法的 URL [%s]: %w", fill(redactedPassword), err)
+	}
+	return dsn, nil
+}
+
+// escapeURLTemplateValue 按占位符在 URL 模板中的位置转义填充的值
+//   - 用户信息（主机之前、@ 之前）: 按 userinfo 的规则转义
+//   - 主机: 原样填充
+//   - 查询参数（? 之后）: QueryEscape
+//   - 路径: PathEscape
+func escapeURLTemplateValue(urlTemplate string, authorityStart, offset int, value string) string {
+	authorityEnd := len(urlTemplate)
+	if end := strings.IndexAny(urlTemplate[authorityStart:], "/?#"); end >= 0 {
+		authorityEnd = authorityStart + end
+	}
+
+	switch {
+	case offset < authorityEnd && strings.Contains(urlTemplate[offset:authorityEnd], "@"):
+		return url.User(value).String()
+	case offset < authorityEnd:
+		return value
+	case strings.Contains(urlTemplate[authorityEnd:offset], "?"):
+		return url.QueryEscape(value)
+	default:
+		return url.PathEscape(value)
+	}
+}
+
+// redactPassword 把连接数据库失败的错误信息中的密码（包括转义后的形式）替换为 xxxxx
+// 驱动解析连接串失败时可能会把连接串带到错误信息中
+func redactPassword(err error, cfg config.GenerateConfig) error {
+	password, resolveErr := cfg.ResolvePassword()
+	if err == nil || resolveErr != nil || password == "" {
+		return err
+	}
+	message := err.Error()
+	for _, form := range []string{password, url.User(password).String(), url.QueryEscape(password), url.PathEscape(password)} {
+		message = strings.ReplaceAll(message, form, redactedPassword)
+	}
+	if message == err.Error() {
+		return err
+	}
+	return errors.New(message)
+}
+
+// quotePostgresValue 按 libpq 的规则为连接参数值加引号
+// 包含空格、引号、反斜杠或为空的值需要用单引号包裹，并转义其中的单引号和反斜杠
+func quotePostgresValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// buildTLSConfig 根据配置构建 tls.Config
+//   - require: 加密连接，但不校验服务端证书
+//   - verify-ca: 校验服务端证书由受信任的 CA 签发，不校验主机名
+//   - verify-full: 校验服务端证书和主机名
+func buildTLSConfig(cfg config.TLSConfig, host string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: host}
+	if cfg.ServerName != "" {
+		tlsConfig.ServerName = cfg.ServerName
+	}
+
+	if cfg.CAFile != "" {
+		byts, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(byts) {
+			return nil, fmt.Errorf("解析 CA 证书失败: %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	switch cfg.Mode {
+	case "require":
+		tlsConfig.InsecureSkipVerify = true
+	case "verify-ca":
+		// 跳过默认校验（包含主机名校验），改为只校验证书链
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return fmt.Errorf("服务端未提供证书")
+			}
+			intermediates := x509.NewCertPool()
+			for _, certificate := range state.PeerCertificates[1:] {
+				intermediates.AddCert(certificate)
+			}
+			_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+				Roots:         tlsConfig.RootCAs,
+				Intermediates: intermediates,
+			})
+			return err
+		}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,218 @@
+package parser
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LingoJack/model_infrax/config"
+)
+
+func TestMysqlDSN(t *testing.T) {
+	cfg := config.GenerateConfig{
+		Host:         "127.0.0.1",
+		Port:         3306,
+		Username:     "root",
+		Password:     "p@ss",
+		DatabaseName: "test_db",
+	}
+
+	dsn, err := mysqlDSN(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(dsn, "root:p@ss@tcp(127.0.0.1:3306)/test_db?") || !strings.Contains(dsn, "charset=utf8mb4") || !strings.Contains(dsn, "parseTime=true") {
+		t.Errorf("tcp 连接串不正确: %s", dsn)
+	}
+
+	socketCfg := cfg
+	socketCfg.Socket = "/tmp/mysql.sock"
+	socketCfg.ConnectTimeout = 3 * time.Second
+	dsn, err = mysqlDSN(socketCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(dsn, "root:p@ss@unix(/tmp/mysql.sock)/test_db?") || !strings.Contains(dsn, "timeout=3s") {
+		t.Errorf("socket 连接串不正确: %s", dsn)
+	}
+
+	templateCfg := cfg
+	templateCfg.URLTemplate = "mysql://%s:%s@tcp(%s:%d)/%s?charset=utf8mb4"
+	dsn, err = mysqlDSN(templateCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dsn != "root:p@ss@tcp(127.0.0.1:3306)/test_db?charset=utf8mb4" {
+		t.Errorf("url_template 填充不正确: %s", dsn)
+	}
+
+	// dsn 优先级最高，原样返回
+	rawCfg := templateCfg
+	rawCfg.DSN = "user:pwd@tcp(db:3306)/other"
+	if dsn, _ = mysqlDSN(rawCfg); dsn != rawCfg.DSN {
+		t.Errorf("应直接使用 dsn: %s", dsn)
+	}
+}
+
+func TestPostgresDSN(t *testing.T) {
+	cfg := config.GenerateConfig{
+		Host:           "localhost",
+		Port:           5432,
+		Username:       "postgres",
+		Password:       "it's secret",
+		DatabaseName:   "test_db",
+		ConnectTimeout: 1500 * time.Millisecond,
+		TLS:            config.TLSConfig{Mode: "verify-full", CAFile: "/etc/ssl/ca.pem"},
+	}
+
+	dsn, err := postgresDSN(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"host=localhost",
+		`password='it\'s secret'`,
+		"sslmode=verify-full",
+		"port=5432",
+		"connect_timeout=2",
+		"sslrootcert=/etc/ssl/ca.pem",
+	} {
+		if !strings.Contains(dsn, expected) {
+			t.Errorf("连接串缺少 %s: %s", expected, dsn)
+		}
+	}
+
+	cfg.TLS = config.TLSConfig{}
+	if dsn, _ = postgresDSN(cfg); !strings.Contains(dsn, "sslmode=disable") {
+		t.Errorf("未配置 TLS 时应为 sslmode=disable: %s", dsn)
+	}
+}
+
+func TestClickhouseDSN(t *testing.T) {
+	cfg := config.GenerateConfig{
+		Host:           "127.0.0.1",
+		Port:           9000,
+		Username:       "default",
+		Password:       "a&b",
+		DatabaseName:   "analytics",
+		ConnectTimeout: 500 * time.Millisecond,
+		TLS:            config.TLSConfig{Mode: "require"},
+	}
+
+	dsn, err := clickhouseDSN(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(dsn, "tcp://127.0.0.1:9000?") {
+		t.Errorf("连接串地址不正确: %s", dsn)
+	}
+	for _, expected := range []string{"database=analytics", "password=a%26b", "timeout=0.5", "secure=true", "tls_config=" + tlsConfigName} {
+		if !strings.Contains(dsn, expected) {
+			t.Errorf("连接串缺少 %s: %s", expected, dsn)
+		}
+	}
+}
+
+// TestFillURLTemplate URL 形式的模板按位置转义用户名、密码和数据库名，错误信息中不包含密码
+func TestFillURLTemplate(t *testing.T) {
+	cfg := config.GenerateConfig{
+		Host:         "127.0.0.1",
+		Port:         5432,
+		Username:     "admin@corp",
+		Password:     "p@ss:w/rd?#&%",
+		DatabaseName: "test db",
+	}
+
+	cfg.URLTemplate = "postgres://%s:%s@%s:%d/%s?sslmode=disable"
+	dsn, err := postgresDSN(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("填充后应为合法的 URL: %s, %v", dsn, err)
+	}
+	if password, _ := u.User.Password(); u.User.Username() != cfg.Username || password != cfg.Password {
+		t.Errorf("用户信息转义不正确: %s", dsn)
+	}
+	if u.Host != "127.0.0.1:5432" || u.Path != "/test db" || u.Query().Get("sslmode") != "disable" {
+		t.Errorf("主机、路径或查询参数不正确: %s", dsn)
+	}
+
+	cfg.Port = 9000
+	cfg.URLTemplate = "clickhouse://%s:%s@%s:%d?database=%s"
+	if dsn, err = clickhouseDSN(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if u, err = url.Parse(dsn); err != nil {
+		t.Fatalf("填充后应为合法的 URL: %s, %v", dsn, err)
+	}
+	if password, _ := u.User.Password(); password != cfg.Password || u.Query().Get("database") != cfg.DatabaseName {
+		t.Errorf("密码或查询参数转义不正确: %s", dsn)
+	}
+
+	// go-sql-driver 的连接串不是 URL，原样填充
+	cfg.URLTemplate = "mysql://%s:%s@tcp(%s:%d)/%s"
+	if dsn, err = mysqlDSN(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if dsn != "admin@corp:p@ss:w/rd?#&%@tcp(127.0.0.1:9000)/test db" {
+		t.Errorf("MySQL 连接串应原样填充: %s", dsn)
+	}
+
+	cfg.URLTemplate = "postgres://%s:%s@[%s:%d/%s"
+	_, err = postgresDSN(cfg)
+	if err == nil {
+		t.Fatal("填充后不是合法的 URL 时应返回错误")
+	}
+	if strings.Contains(err.Error(), "p@ss") || strings.Contains(err.Error(), url.User(cfg.Password).String()) || !strings.Contains(err.Error(), redactedPassword) {
+		t.Errorf("错误信息中不应包含密码: %v", err)
+	}
+}
+
+// TestRedactPassword 连接失败的错误信息中的密码被替换
+func TestRedactPassword(t *testing.T) {
+	cfg := config.GenerateConfig{Password: "s3cret/pwd"}
+	err := redactPassword(errors.New("cannot parse `tcp://u:s3cret%2Fpwd@db` and s3cret/pwd"), cfg)
+	if strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("错误信息中不应包含密码: %v", err)
+	}
+	if err = redactPassword(errors.New("connection refused"), cfg); err.Error() != "connection refused" {
+		t.Errorf("不包含密码的错误应原样返回: %v", err)
+	}
+}
+
+func TestResolvePassword(t *testing.T) {
+	t.Setenv("MODEL_INFRAX_TEST_PASSWORD", "from-env")
+	password, err := config.GenerateConfig{PasswordEnv: "MODEL_INFRAX_TEST_PASSWORD"}.ResolvePassword()
+	if err != nil || password != "from-env" {
+		t.Errorf("应从环境变量读取密码: %q, %v", password, err)
+	}
+
+	if _, err = (config.GenerateConfig{PasswordEnv: "MODEL_INFRAX_TEST_PASSWORD_UNSET"}).ResolvePassword(); err == nil {
+		t.Error("环境变量未设置时应返回错误")
+	}
+
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err = os.WriteFile(passwordFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	password, err = config.GenerateConfig{PasswordFile: passwordFile}.ResolvePassword()
+	if err != nil || password != "from-file" {
+		t.Errorf("应从文件读取密码并去掉换行: %q, %v", password, err)
+	}
+
+	_, err = config.NewBuilder().
+		DatabaseMode("127.0.0.1", 3306, "test_db", "root", "").
+		PasswordFromEnv("MODEL_INFRAX_TEST_PASSWORD").
+		PasswordFromFile(passwordFile).
+		AllTables().
+		Build()
+	if err == nil {
+		t.Error("同时配置多个密码来源时应返回错误")
+	}
+}
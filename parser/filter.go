@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"log"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
+)
+
+// tablePattern include_tables、exclude_tables 中的一个表名模式
+type tablePattern struct {
+	raw   string
+	match func(tableName string) bool
+}
+
+// tableFilter 按 all_tables、table_names、include_tables、exclude_tables 过滤表，所有解析器共用
+type tableFilter struct {
+	allTables  bool
+	tableNames []string
+	includes   []tablePattern
+	excludes   []tablePattern
+}
+
+// newTableFilter 根据生成配置编译表过滤规则
+func newTableFilter(generateConfig config.GenerateConfig) (*tableFilter, error) {
+	includes, err := compileTablePatterns(generateConfig.IncludeTables)
+	if err != nil {
+		return nil, err
+	}
+	excludes, err := compileTablePatterns(generateConfig.ExcludeTables)
+	if err != nil {
+		return nil, err
+	}
+	return &tableFilter{
+		allTables:  generateConfig.AllTables,
+		tableNames: generateConfig.TableNames,
+		includes:   includes,
+		excludes:   excludes,
+	}, nil
+}
+
+// compileTablePatterns 编译表名模式，以 re: 开头的为正则表达式，其余按 glob 匹配
+func compileTablePatterns(patterns []string) ([]tablePattern, error) {
+	compiled := make([]tablePattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		if expr, ok := strings.CutPrefix(pattern, config.TableRegexpPrefix); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, err
+			}
+			compiled = append(compiled, tablePattern{raw: pattern, match: re.MatchString})
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, tablePattern{raw: pattern, match: func(tableName string) bool {
+			matched, _ := path.Match(pattern, tableName)
+			return matched
+		}})
+	}
+	return compiled, nil
+}
+
+// filter 返回过滤后的表（保持原有顺序），以及没有匹配到任何表的表名和模式
+func (f *tableFilter) filter(schemas []model.Schema) (filtered []model.Schema, unmatched []string) {
+	matchedTableNames := make(map[string]bool)
+	matchedPatterns := make(map[string]bool)
+	for _, schema := range schemas {
+		included := f.allTables
+		if lo.Contains(f.tableNames, schema.Name) {
+			matchedTableNames[schema.Name] = true
+			included = true
+		}
+		for _, include := range f.includes {
+			if include.match(schema.Name) {
+				matchedPatterns[include.raw] = true
+				included = true
+			}
+		}
+		excluded := false
+		for _, exclude := range f.excludes {
+			if exclude.match(schema.Name) {
+				matchedPatterns[exclude.raw] = true
+				excluded = true
+			}
+		}
+		if included && !excluded {
+			filtered = append(filtered, schema)
+		}
+	}
+
+	for _, tableName := range f.tableNames {
+		if !matchedTableNames[tableName] {
+			unmatched = append(unmatched, tableName)
+		}
+	}
+	for _, pattern := range append(append([]tablePattern(nil), f.includes...), f.excludes...) {
+		if !matchedPatterns[pattern.raw] {
+			unmatched = append(unmatched, pattern.raw)
+		}
+	}
+	return filtered, lo.Uniq(unmatched)
+}
+
+// filterTables 各解析器 FilterTables 的公共实现，配置的表名或模式没有匹配到任何表时给出警告
+func filterTables(generateConfig config.GenerateConfig, schemas []model.Schema) []model.Schema {
+	filter, err := newTableFilter(generateConfig)
+	if err != nil {
+		// 加载配置时已经校验过模式，只有绕过校验直接构造配置时才会走到这里
+		log.Printf("⚠️ 表过滤规则不合法，不生成任何表: %v", err)
+		return nil
+	}
+	filtered, unmatched := filter.filter(schemas)
+	for _, name := range unmatched {
+		log.Printf("⚠️ 配置的表名或模式没有匹配到任何表: %s", name)
+	}
+	return filtered
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
+)
+
+func TestTableFilter(t *testing.T) {
+	schemas := lo.Map([]string{"t_user", "t_order_item", "t_order_item_bak", "t_order_log_tmp", "t_order_pay", "t_audit"}, func(name string, _ int) model.Schema {
+		return model.Schema{Name: name}
+	})
+
+	for _, testCase := range []struct {
+		name      string
+		config    config.GenerateConfig
+		expected  string
+		unmatched string
+	}{
+		{
+			name:     "所有表",
+			config:   config.GenerateConfig{AllTables: true},
+			expected: "[t_user t_order_item t_order_item_bak t_order_log_tmp t_order_pay t_audit]",
+		},
+		{
+			name:      "精确表名",
+			config:    config.GenerateConfig{TableNames: []string{"t_audit", "t_user", "t_missing"}},
+			expected:  "[t_user t_audit]",
+			unmatched: "[t_missing]",
+		},
+		{
+			name: "glob 包含与排除",
+			config: config.GenerateConfig{
+				IncludeTables: []string{"t_order_*"},
+				ExcludeTables: []string{"*_bak", "*_tmp", "*_old"},
+			},
+			expected:  "[t_order_item t_order_pay]",
+			unmatched: "[*_old]",
+		},
+		{
+			name: "正则表达式与表名合并",
+			config: config.GenerateConfig{
+				TableNames:    []string{"t_audit"},
+				IncludeTables: []string{`re:^t_(user|order_pay)$`, "t_goods_*"},
+			},
+			expected:  "[t_user t_order_pay t_audit]",
+			unmatched: "[t_goods_*]",
+		},
+		{
+			name: "排除优先于所有表",
+			config: config.GenerateConfig{
+				AllTables:     true,
+				ExcludeTables: []string{`re:_(bak|tmp)$`, "t_order_*"},
+			},
+			expected: "[t_user t_audit]",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			filter, err := newTableFilter(testCase.config)
+			if err != nil {
+				t.Fatalf("编译过滤规则失败: %v", err)
+			}
+			filtered, unmatched := filter.filter(schemas)
+			names := lo.Map(filtered, func(schema model.Schema, _ int) string {
+				return schema.Name
+			})
+			if fmt.Sprint(names) != testCase.expected {
+				t.Errorf("过滤结果不正确: %v, 期望: %s", names, testCase.expected)
+			}
+			if testCase.unmatched != "" && fmt.Sprint(unmatched) != testCase.unmatched || testCase.unmatched == "" && len(unmatched) > 0 {
+				t.Errorf("未匹配的表名不正确: %v, 期望: %s", unmatched, testCase.unmatched)
+			}
+		})
+	}
+}
+
+func TestTableFilterInvalidPattern(t *testing.T) {
+	for _, builder := range []*config.ConfiggerBuilder{
+		config.NewBuilder().StatementMode("schema.sql").OutputPath("./output").IncludeTables("t_[order"),
+		config.NewBuilder().StatementMode("schema.sql").OutputPath("./output").AllTables().ExcludeTables("re:t_(order"),
+	} {
+		if _, err := builder.Build(); err == nil {
+			t.Errorf("不合法的表名模式应返回错误")
+		}
+	}
+	if _, err := newTableFilter(config.GenerateConfig{IncludeTables: []string{"t_[order"}}); err == nil {
+		t.Errorf("不合法的 glob 模式应返回错误")
+	}
+}
@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// golang-migrate: 000001_init.up.sql / 000001_init.down.sql
+	migrateFileRegexp = regexp.MustCompile(`^(\d+)_.*\.(up|down)\.sql$`)
+	// Flyway: V1__init.sql、V1_2__add_index.sql、U1__undo.sql、R__views.sql
+	flywayFileRegexp = regexp.MustCompile(`^([VUR])(\d+(?:[._]\d+)*)?__.*\.sql$`)
+	// goose 及其他以版本号开头的文件: 20240101120000_init.sql、001_init.sql
+	versionedFileRegexp = regexp.MustCompile(`^(\d+)_.*\.sql$`)
+	// 版本号按 . 或 _ 分段
+	versionSeparatorRegexp = regexp.MustCompile(`[._]`)
+)
+
+// sqlFile 待执行的SQL文件
+type sqlFile struct {
+	Path    string   // 文件路径
+	Content string   // 需要执行的SQL内容（goose 文件只保留 Up 部分）
+	Version []string // 版本号的各段数字，未带版本号的文件为nil
+}
+
+// loadSQLFiles 读取 sql_file_path 指向的SQL文件
+//   - 普通文件: 直接读取
+//   - 目录或 glob: 读取匹配的 .sql 文件，按迁移工具的规则排序，只保留 up 部分
+//
+// 支持的迁移文件布局:
+//   - goose: 001_init.sql，文件中 "-- +goose Up" 与 "-- +goose Down" 之间的部分
+//   - golang-migrate: 001_init.up.sql，忽略 .down.sql
+//   - Flyway: V1__init.sql，忽略 U 开头的撤销脚本，R__ 开头的可重复脚本在所有版本化脚本之后按名称执行
+//
+// 不带版本号的文件在版本化文件之后按文件名执行
+func loadSQLFiles(sqlFilePath string) ([]sqlFile, error) {
+	info, err := os.Stat(sqlFilePath)
+	if err == nil && !info.IsDir() {
+		file, err := readSQLFile(sqlFilePath)
+		if err != nil {
+			return nil, err
+		}
+		return []sqlFile{file}, nil
+	}
+
+	var paths []string
+	switch {
+	case err == nil:
+		paths, err = filepath.Glob(filepath.Join(sqlFilePath, "*.sql"))
+	case strings.ContainsAny(sqlFilePath, "*?["):
+		paths, err = filepath.Glob(sqlFilePath)
+	default:
+		return nil, fmt.Errorf("读取SQL文件失败 [%s]: %w", sqlFilePath, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("匹配SQL文件失败 [%s]: %w", sqlFilePath, err)
+	}
+
+	var versioned, unversioned []sqlFile
+	for _, path := range paths {
+		version, apply := migrationVersion(filepath.Base(path))
+		if !apply {
+			log.Printf("⚠️ 跳过回滚迁移文件: %s", path)
+			continue
+		}
+
+		file, err := readSQLFile(path)
+		if err != nil {
+			return nil, err
+		}
+		file.Version = version
+		if version == nil {
+			unversioned = append(unversioned, file)
+		} else {
+			versioned = append(versioned, file)
+		}
+	}
+	if len(versioned)+len(unversioned) == 0 {
+		return nil, fmt.Errorf("未找到SQL文件: %s", sqlFilePath)
+	}
+
+	sort.SliceStable(versioned, func(i, j int) bool {
+		return compareVersion(versioned[i].Version, versioned[j].Version) < 0
+	})
+	for i := 1; i < len(versioned); i++ {
+		if compareVersion(versioned[i-1].Version, versioned[i].Version) == 0 {
+			return nil, fmt.Errorf("迁移版本号重复: %s, %s", versioned[i-1].Path, versioned[i].Path)
+		}
+	}
+	// filepath.Glob 的结果已按文件名排序
+	return append(versioned, unversioned...), nil
+}
+
+// migrationVersion 从文件名中解析版本号
+// apply 为 false 表示该文件是回滚脚本（golang-migrate 的 .down.sql、Flyway 的 U 脚本），不需要执行
+func migrationVersion(fileName string) (version []string, apply bool) {
+	if matches := migrateFileRegexp.FindStringSubmatch(fileName); matches != nil {
+		return []string{matches[1]}, matches[2] == "up"
+	}
+	if matches := flywayFileRegexp.FindStringSubmatch(fileName); matches != nil {
+		switch matches[1] {
+		case "V":
+			if matches[2] != "" {
+				return versionSeparatorRegexp.Split(matches[2], -1), true
+			}
+		case "U":
+			return nil, false
+		}
+		// R__ 可重复脚本没有版本号
+		return nil, true
+	}
+	if matches := versionedFileRegexp.FindStringSubmatch(fileName); matches != nil {
+		return []string{matches[1]}, true
+	}
+	return nil, true
+}
+
+// compareVersion 按段比较版本号，每段按数值大小比较（1.10 大于 1.9）
+func compareVersion(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := strings.TrimLeft(a[i], "0"), strings.TrimLeft(b[i], "0")
+		if len(x) != len(y) {
+			return len(x) - len(y)
+		}
+		if c := strings.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+// readSQLFile 读取SQL文件，goose 格式的文件只保留 Up 部分
+func readSQLFile(path string) (sqlFile, error) {
+	byts, err := os.ReadFile(path)
+	if err != nil {
+		return sqlFile{}, fmt.Errorf("读取SQL文件失败 [%s]: %w", path, err)
+	}
+
+	content := string(byts)
+	if strings.Contains(content, "-- +goose") {
+		content = extractGooseUp(content)
+	}
+	return sqlFile{Path: path, Content: content}, nil
+}
+
+// extractGooseUp 提取 goose 迁移文件中 "-- +goose Up" 部分的SQL
+// 其余注解（StatementBegin、StatementEnd 等）所在的行会被去掉，Down 部分被忽略
+func extractGooseUp(content string) string {
+	var builder strings.Builder
+	up := false
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "--" && strings.HasPrefix(fields[1], "+goose") {
+			if len(fields) >= 3 {
+				switch strings.ToLower(fields[2]) {
+				case "up":
+					up = true
+				case "down":
+					up = false
+				}
+			}
+			// 保留空行，使后续语句的行号与原文件一致
+			builder.WriteString("\n")
+			continue
+		}
+		if up {
+			builder.WriteString(line)
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
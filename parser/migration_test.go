@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/samber/lo"
+)
+
+// writeSQLFiles 在临时目录中创建SQL文件，返回目录路径
+func writeSQLFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func sqlFileNames(files []sqlFile) string {
+	return strings.Join(lo.Map(files, func(file sqlFile, _ int) string {
+		return filepath.Base(file.Path)
+	}), ",")
+}
+
+func TestLoadSQLFilesGolangMigrate(t *testing.T) {
+	dir := writeSQLFiles(t, map[string]string{
+		"10_add_age.up.sql":     "ALTER TABLE t_user ADD COLUMN age int;",
+		"10_add_age.down.sql":   "ALTER TABLE t_user DROP COLUMN age;",
+		"2_add_name.up.sql":     "ALTER TABLE t_user ADD COLUMN name varchar(32);",
+		"2_add_name.down.sql":   "ALTER TABLE t_user DROP COLUMN name;",
+		"001_init.up.sql":       "CREATE TABLE t_user (id bigint);",
+		"001_init.down.sql":     "DROP TABLE t_user;",
+		"README.md":             "# migrations",
+		"zz_seed_data_only.sql": "INSERT INTO t_user (id) VALUES (1);",
+	})
+
+	files, err := loadSQLFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := sqlFileNames(files); names != "001_init.up.sql,2_add_name.up.sql,10_add_age.up.sql,zz_seed_data_only.sql" {
+		t.Errorf("迁移文件顺序不正确: %s", names)
+	}
+}
+
+func TestLoadSQLFilesFlyway(t *testing.T) {
+	dir := writeSQLFiles(t, map[string]string{
+		"V2__add_index.sql":   "CREATE INDEX idx_name ON t_user (name);",
+		"V1_1__add_name.sql":  "ALTER TABLE t_user ADD COLUMN name varchar(32);",
+		"V1__init.sql":        "CREATE TABLE t_user (id bigint);",
+		"V1.10__add_age.sql":  "ALTER TABLE t_user ADD COLUMN age int;",
+		"U2__drop_index.sql":  "DROP INDEX idx_name ON t_user;",
+		"R__refresh_view.sql": "CREATE VIEW v_user AS SELECT * FROM t_user;",
+	})
+
+	files, err := loadSQLFiles(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := sqlFileNames(files); names != "V1__init.sql,V1_1__add_name.sql,V1.10__add_age.sql,V2__add_index.sql,R__refresh_view.sql" {
+		t.Errorf("迁移文件顺序不正确: %s", names)
+	}
+}
+
+func TestLoadSQLFilesDuplicateVersion(t *testing.T) {
+	dir := writeSQLFiles(t, map[string]string{
+		"V1__init.sql":  "CREATE TABLE t_a (id bigint);",
+		"V01__init.sql": "CREATE TABLE t_b (id bigint);",
+	})
+	if _, err := loadSQLFiles(dir); err == nil {
+		t.Error("版本号重复时应返回错误")
+	}
+	if _, err := loadSQLFiles(filepath.Join(dir, "*.missing")); err == nil {
+		t.Error("没有匹配的文件时应返回错误")
+	}
+}
+
+func TestStatementParserGooseDirectory(t *testing.T) {
+	dir := writeSQLFiles(t, map[string]string{
+		"20240101000000_init.sql": `-- +goose Up
+CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);
+
+-- +goose Down
+DROP TABLE t_user;
+`,
+		"20240201000000_add_name.sql": `-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE t_user ADD COLUMN name varchar(32) NOT NULL COMMENT '用户名';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE t_user DROP COLUMN name;
+`,
+	})
+
+	configger, err := config.NewBuilder().StatementMode(dir).AllTables().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	statementParser, err := NewStatementParser(configger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := statementParser.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(schemas) != 1 || len(schemas[0].Columns) != 2 || schemas[0].Columns[1].Comment != "用户名" {
+		t.Errorf("goose 迁移回放结果不正确: %+v", schemas)
+	}
+}
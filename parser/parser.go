@@ -1,8 +1,40 @@
 package parser
 
-import "github.com/LingoJack/model_infrax/model"
+import (
+	"fmt"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/model"
+)
 
 type Parser interface {
 	Parse() (schemas []model.Schema, err error)
 	FilterTables(schemas []model.Schema) (filtered []model.Schema)
 }
+
+// NewDialectParser 根据 generate_config.dialect 创建 database 模式下对应的解析器
+// dialect 为空时默认使用 MySQL
+func NewDialectParser(cfg *config.Configger) (Parser, error) {
+	switch cfg.GenerateConfig.Dialect {
+	case "", "mysql":
+		databaseParser, err := NewDatabaseParser(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return databaseParser, nil
+	case "postgres":
+		postgresParser, err := NewPostgresParser(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return postgresParser, nil
+	case "clickhouse":
+		clickhouseParser, err := NewClickhouseParser(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return clickhouseParser, nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库方言: %s，请使用 'mysql'、'postgres' 或 'clickhouse'", cfg.GenerateConfig.Dialect)
+	}
+}
@@ -0,0 +1,293 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// defaultPostgresSchema postgres 默认的 schema 名称
+const defaultPostgresSchema = "public"
+
+// PostgresParser PostgreSQL 解析器，通过 pg_catalog 读取表结构
+type PostgresParser struct {
+	db         *gorm.DB
+	configger  *config.Configger
+	schemaName string
+}
+
+// NewPostgresParser 创建 PostgreSQL 解析器
+// 未配置 schema_name 时默认解析 public schema
+func NewPostgresParser(cfg *config.Configger) (*PostgresParser, error) {
+	dsn, err := postgresDSN(cfg.GenerateConfig)
+	if err != nil {
+		return nil, fmt.Errorf("构建连接串失败: %w", err)
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", redactPassword(err, cfg.GenerateConfig))
+	}
+
+	schemaName := cfg.GenerateConfig.SchemaName
+	if schemaName == "" {
+		schemaName = defaultPostgresSchema
+	}
+
+	return &PostgresParser{
+		db:         db,
+		configger:  cfg,
+		schemaName: schemaName,
+	}, nil
+}
+
+// postgresTable PostgreSQL 表信息
+type postgresTable struct {
+	TableName    string `gorm:"column:table_name"`    // 表名
+	TableComment string `gorm:"column:table_comment"` // 表注释
+}
+
+// postgresColumn PostgreSQL 列信息
+type postgresColumn struct {
+	TableName     string  `gorm:"column:table_name"`     // 表名
+	ColumnName    string  `gorm:"column:column_name"`    // 列名
+	ColumnType    string  `gorm:"column:column_type"`    // 列类型（format_type 的结果，如 character varying(128)、integer[]）
+	IsNullable    bool    `gorm:"column:is_nullable"`    // 是否允许为NULL
+	ColumnDefault *string `gorm:"column:column_default"` // 默认值表达式（可能为null）
+	IsIdentity    bool    `gorm:"column:is_identity"`    // 是否为 identity 列
+	Collation     string  `gorm:"column:collation"`      // 非默认的排序规则
+	ColumnComment string  `gorm:"column:column_comment"` // 列注释
+	Ordinal       int     `gorm:"column:ordinal"`        // 列序号（attnum，从1开始，删除列后可能不连续）
+}
+
+// postgresIndex PostgreSQL 索引信息，每个索引的键列一行（不包含 INCLUDE 列）
+type postgresIndex struct {
+	TableName  string `gorm:"column:table_name"`   // 表名
+	IndexName  string `gorm:"column:index_name"`   // 索引名
+	IsUnique   bool   `gorm:"column:is_unique"`    // 是否唯一索引（部分唯一索引只在满足 WHERE 条件的行中唯一，视为普通索引）
+	IsPrimary  bool   `gorm:"column:is_primary"`   // 是否主键
+	ColumnName string `gorm:"column:column_name"`  // 列名
+	SeqInIndex int    `gorm:"column:seq_in_index"` // 列在索引中的序号（从1开始）
+	IsDesc     bool   `gorm:"column:is_desc"`      // 是否降序
+	IndexType  string `gorm:"column:index_type"`   // 索引类型（btree、hash、gin、gist 等）
+}
+
+const postgresTablesSQL = `
+select c.relname as table_name,
+       coalesce(obj_description(c.oid, 'pg_class'), '') as table_comment
+from pg_class c
+         join pg_namespace n on n.oid = c.relnamespace
+where n.nspname = ?
+  and c.relkind in ('r', 'p')
+  and not c.relispartition
+order by c.relname`
+
+const postgresColumnsSQL = `
+select c.relname as table_name,
+       a.attname as column_name,
+       format_type(a.atttypid, a.atttypmod) as column_type,
+       not a.attnotnull as is_nullable,
+       pg_get_expr(d.adbin, d.adrelid) as column_default,
+       a.attidentity <> '' as is_identity,
+       case when coll.collname is null or coll.collname = 'default' then '' else coll.collname end as collation,
+       coalesce(col_description(c.oid, a.attnum), '') as column_comment,
+       a.attnum as ordinal
+from pg_attribute a
+         join pg_class c on c.oid = a.attrelid
+         join pg_namespace n on n.oid = c.relnamespace
+         left join pg_attrdef d on d.adrelid = a.attrelid and d.adnum = a.attnum
+         left join pg_collation coll on coll.oid = a.attcollation
+where n.nspname = ?
+  and c.relkind in ('r', 'p')
+  and a.attnum > 0
+  and not a.attisdropped
+order by c.relname, a.attnum`
+
+// 只查询索引的键列（k.ord <= indnkeyatts），INCLUDE 列不参与索引查找
+// 表达式索引（indexprs 不为空，键列中 attnum 为 0）只保留其余的列会得到语义不同的索引，整个跳过
+const postgresIndexesSQL = `
+select t.relname as table_name,
+       i.relname as index_name,
+       ix.indisunique and ix.indpred is null as is_unique,
+       ix.indisprimary as is_primary,
+       a.attname as column_name,
+       k.ord as seq_in_index,
+       (ix.indoption[k.ord - 1] & 1) = 1 as is_desc,
+       am.amname as index_type
+from pg_index ix
+         join pg_class t on t.oid = ix.indrelid
+         join pg_class i on i.oid = ix.indexrelid
+         join pg_am am on am.oid = i.relam
+         join pg_namespace n on n.oid = t.relnamespace
+         cross join lateral unnest(ix.indkey) with ordinality as k(attnum, ord)
+         join pg_attribute a on a.attrelid = t.oid and a.attnum = k.attnum
+where n.nspname = ?
+  and ix.indexprs is null
+  and k.ord <= ix.indnkeyatts
+order by t.relname, ix.indisprimary desc, i.relname, k.ord`
+
+// 外键动作在 pg_constraint 中以单个字符表示，这里转换为与 MySQL 一致的名称
+const postgresForeignKeysSQL = `
+select t.relname as table_name,
+       con.conname as constraint_name,
+       a.attname as column_name,
+       k.ord as ordinal_position,
+       rt.relname as referenced_table_name,
+       ra.attname as referenced_column_name,
+       case con.confupdtype when 'r' then 'RESTRICT' when 'c' then 'CASCADE' when 'n' then 'SET NULL' when 'd' then 'SET DEFAULT' else 'NO ACTION' end as update_rule,
+       case con.confdeltype when 'r' then 'RESTRICT' when 'c' then 'CASCADE' when 'n' then 'SET NULL' when 'd' then 'SET DEFAULT' else 'NO ACTION' end as delete_rule
+from pg_constraint con
+         join pg_class t on t.oid = con.conrelid
+         join pg_class rt on rt.oid = con.confrelid
+         join pg_namespace n on n.oid = t.relnamespace
+         cross join lateral unnest(con.conkey, con.confkey) with ordinality as k(attnum, ref_attnum, ord)
+         join pg_attribute a on a.attrelid = con.conrelid and a.attnum = k.attnum
+         join pg_attribute ra on ra.attrelid = con.confrelid and ra.attnum = k.ref_attnum
+where n.nspname = ?
+  and con.contype = 'f'
+order by t.relname, con.conname, k.ord`
+
+// Parse 解析 schema 下所有表的结构
+// 列、索引、外键都通过一次批量查询获取，再按表名分组
+func (p *PostgresParser) Parse() (schemas []model.Schema, err error) {
+	var tables []postgresTable
+	if err = p.db.Raw(postgresTablesSQL, p.schemaName).Scan(&tables).Error; err != nil {
+		return nil, fmt.Errorf("查询数据库表失败: %w", err)
+	}
+
+	var columns []postgresColumn
+	if err = p.db.Raw(postgresColumnsSQL, p.schemaName).Scan(&columns).Error; err != nil {
+		return nil, fmt.Errorf("查询表字段失败: %w", err)
+	}
+
+	var indexes []postgresIndex
+	if err = p.db.Raw(postgresIndexesSQL, p.schemaName).Scan(&indexes).Error; err != nil {
+		return nil, fmt.Errorf("查询表索引失败: %w", err)
+	}
+
+	var foreignKeys []foreignKeyRow
+	if err = p.db.Raw(postgresForeignKeysSQL, p.schemaName).Scan(&foreignKeys).Error; err != nil {
+		return nil, fmt.Errorf("查询表外键失败: %w", err)
+	}
+
+	table2Columns := lo.GroupBy(columns, func(column postgresColumn) string {
+		return column.TableName
+	})
+	table2Indexes := lo.GroupBy(indexes, func(index postgresIndex) string {
+		return index.TableName
+	})
+	table2ForeignKeys := lo.GroupBy(foreignKeys, func(foreignKey foreignKeyRow) string {
+		return foreignKey.TableName
+	})
+
+	for _, table := range tables {
+		schemas = append(schemas, buildPostgresSchema(table, p.schemaName, table2Columns[table.TableName], table2Indexes[table.TableName], table2ForeignKeys[table.TableName]))
+	}
+
+	return applyAnnotations(schemas), nil
+}
+
+// buildPostgresSchema 将 pg_catalog 中查询到的行转换为表结构，索引行需已按 主键优先、索引名、列序号 排序
+func buildPostgresSchema(table postgresTable, schemaName string, fields []postgresColumn, indexRows []postgresIndex, foreignKeyRows []foreignKeyRow) model.Schema {
+	schema := model.Schema{
+		Name:    table.TableName,
+		Comment: table.TableComment,
+	}
+	// 非 public schema 的表需要带上 schema 前缀才能被访问
+	if schemaName != defaultPostgresSchema {
+		schema.Namespace = schemaName
+	}
+
+	columnIndexMap := make(map[string]int)
+	for _, field := range fields {
+		column := model.Column{
+			ColumnName:      field.ColumnName,
+			Collate:         field.Collation,
+			Comment:         field.ColumnComment,
+			Type:            field.ColumnType,
+			IsNullable:      field.IsNullable,
+			OrdinalPosition: field.Ordinal,
+		}
+		// serial / identity 列视为自增列，nextval(...) 默认值由数据库生成，不写入模型
+		if field.IsIdentity || isPostgresSequenceDefault(field.ColumnDefault) {
+			column.IsAutoIncrement = true
+		} else {
+			column.Default = field.ColumnDefault
+		}
+		schema.Columns = append(schema.Columns, column)
+		columnIndexMap[column.ColumnName] = len(schema.Columns) - 1
+	}
+
+	// 索引行已按索引名和列序号排序，按出现顺序分组即可保证结果稳定
+	var indexNames []string
+	indexName2Rows := make(map[string][]postgresIndex)
+	for _, row := range indexRows {
+		if _, exists := indexName2Rows[row.IndexName]; !exists {
+			indexNames = append(indexNames, row.IndexName)
+		}
+		indexName2Rows[row.IndexName] = append(indexName2Rows[row.IndexName], row)
+	}
+
+	for _, indexName := range indexNames {
+		rows := indexName2Rows[indexName]
+		// 索引中有找不到的列时，只保留其余的列会得到语义不同的索引，整个跳过
+		if lo.SomeBy(rows, func(row postgresIndex) bool {
+			_, exists := columnIndexMap[row.ColumnName]
+			return !exists
+		}) {
+			continue
+		}
+		isPrimary, isUnique := rows[0].IsPrimary, rows[0].IsUnique
+
+		index := model.Index{
+			IndexName: indexName,
+			IsUnique:  isUnique,
+			IndexType: strings.ToUpper(rows[0].IndexType),
+		}
+		for _, row := range rows {
+			colIdx := columnIndexMap[row.ColumnName]
+			schema.Columns[colIdx].IsIndexed = true
+			if isUnique {
+				schema.Columns[colIdx].IsUnique = true
+			}
+			if isPrimary {
+				schema.Columns[colIdx].IsPrimaryKey = true
+			}
+			index.Columns = append(index.Columns, schema.Columns[colIdx])
+			index.Parts = append(index.Parts, model.IndexPart{
+				ColumnName: row.ColumnName,
+				Seq:        row.SeqInIndex,
+				Direction:  lo.Ternary(row.IsDesc, "DESC", "ASC"),
+			})
+		}
+
+		switch {
+		case isPrimary:
+			// 与 MySQL 保持一致，主键索引统一命名为 PRIMARY
+			index.IndexName = "PRIMARY"
+			schema.PrimaryKey = index
+		case isUnique:
+			schema.UniqueIndex = append(schema.UniqueIndex, index)
+		default:
+			schema.Indexes = append(schema.Indexes, index)
+		}
+	}
+
+	schema.ForeignKeys = buildForeignKeys(foreignKeyRows)
+	return schema
+}
+
+// FilterTables 根据配置文件过滤表，规则见 filterTables
+func (p *PostgresParser) FilterTables(schemas []model.Schema) (filtered []model.Schema) {
+	return filterTables(p.configger.GenerateConfig, schemas)
+}
+
+// isPostgresSequenceDefault 判断默认值是否为序列（serial 类型的 nextval(...)）
+func isPostgresSequenceDefault(defaultValue *string) bool {
+	return defaultValue != nil && strings.HasPrefix(*defaultValue, "nextval(")
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/samber/lo"
+)
+
+// describeIndexes 将表结构中的索引描述为 主键 | 唯一索引 | 普通索引，每个索引为 名称[列 方向...]
+func describeIndexes(schema model.Schema) string {
+	describe := func(index model.Index, _ int) string {
+		parts := lo.Map(index.Parts, func(part model.IndexPart, _ int) string {
+			return part.ColumnName + " " + part.Direction
+		})
+		return fmt.Sprintf("%s[%s]", index.IndexName, strings.Join(parts, ", "))
+	}
+	primaryKey := ""
+	if len(schema.PrimaryKey.Columns) > 0 {
+		primaryKey = describe(schema.PrimaryKey, 0)
+	}
+	return strings.Join([]string{
+		primaryKey,
+		strings.Join(lo.Map(schema.UniqueIndex, describe), " "),
+		strings.Join(lo.Map(schema.Indexes, describe), " "),
+	}, " | ")
+}
+
+func TestBuildPostgresSchema(t *testing.T) {
+	fields := []postgresColumn{
+		{ColumnName: "id", ColumnType: "bigint", Ordinal: 1, ColumnDefault: lo.ToPtr("nextval('t_user_id_seq'::regclass)")},
+		{ColumnName: "tenant_id", ColumnType: "bigint", Ordinal: 2},
+		{ColumnName: "name", ColumnType: "character varying(64)", Ordinal: 3, ColumnDefault: lo.ToPtr("''::character varying")},
+		{ColumnName: "email", ColumnType: "text", Ordinal: 5, IsNullable: true},
+	}
+	primaryKey := postgresIndex{IndexName: "t_user_pkey", IsPrimary: true, IsUnique: true, ColumnName: "id", SeqInIndex: 1, IndexType: "btree"}
+
+	for _, testCase := range []struct {
+		name      string
+		rows      []postgresIndex
+		expected  string
+		unique    []string // 应标记为唯一的列
+		unindexed []string // 不应标记为索引的列
+	}{
+		{
+			name: "主键、唯一索引和普通索引分开存放",
+			rows: []postgresIndex{
+				primaryKey,
+				{IndexName: "idx_name", ColumnName: "name", SeqInIndex: 1, IsDesc: true, IndexType: "btree"},
+				{IndexName: "uk_tenant_name", IsUnique: true, ColumnName: "tenant_id", SeqInIndex: 1, IndexType: "btree"},
+				{IndexName: "uk_tenant_name", IsUnique: true, ColumnName: "name", SeqInIndex: 2, IndexType: "btree"},
+			},
+			expected:  "PRIMARY[id ASC] | uk_tenant_name[tenant_id ASC, name ASC] | idx_name[name DESC]",
+			unique:    []string{"id", "tenant_id", "name"},
+			unindexed: []string{"email"},
+		},
+		{
+			name: "同一个索引的行不相邻时按首次出现的顺序分组",
+			rows: []postgresIndex{
+				{IndexName: "idx_tenant_email", ColumnName: "tenant_id", SeqInIndex: 1, IndexType: "btree"},
+				{IndexName: "idx_name", ColumnName: "name", SeqInIndex: 1, IndexType: "hash"},
+				{IndexName: "idx_tenant_email", ColumnName: "email", SeqInIndex: 2, IndexType: "btree"},
+			},
+			expected: " |  | idx_tenant_email[tenant_id ASC, email ASC] idx_name[name ASC]",
+		},
+		{
+			// 查询中部分唯一索引的 is_unique 为 false，只能作为普通索引
+			name: "部分唯一索引作为普通索引",
+			rows: []postgresIndex{
+				primaryKey,
+				{IndexName: "uk_email_active", ColumnName: "email", SeqInIndex: 1, IndexType: "btree"},
+			},
+			expected: "PRIMARY[id ASC] |  | uk_email_active[email ASC]",
+			unique:   []string{"id"},
+		},
+		{
+			name: "包含找不到的列的索引整个跳过",
+			rows: []postgresIndex{
+				{IndexName: "idx_tenant_dropped", ColumnName: "tenant_id", SeqInIndex: 1, IndexType: "btree"},
+				{IndexName: "idx_tenant_dropped", ColumnName: "dropped", SeqInIndex: 2, IndexType: "btree"},
+				{IndexName: "uk_email", IsUnique: true, ColumnName: "email", SeqInIndex: 1, IndexType: "btree"},
+			},
+			expected:  " | uk_email[email ASC] | ",
+			unique:    []string{"email"},
+			unindexed: []string{"tenant_id"},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			schema := buildPostgresSchema(postgresTable{TableName: "t_user", TableComment: "用户表"}, defaultPostgresSchema, fields, testCase.rows, nil)
+
+			if actual := describeIndexes(schema); actual != testCase.expected {
+				t.Errorf("索引分组不正确:\n实际: %s\n期望: %s", actual, testCase.expected)
+			}
+			for _, column := range schema.Columns {
+				if isUnique := lo.Contains(testCase.unique, column.ColumnName); column.IsUnique != isUnique {
+					t.Errorf("%s 列的唯一标记不正确: %v", column.ColumnName, column.IsUnique)
+				}
+				if lo.Contains(testCase.unindexed, column.ColumnName) && column.IsIndexed {
+					t.Errorf("%s 列不应标记为索引列", column.ColumnName)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPostgresSchemaColumns(t *testing.T) {
+	fields := []postgresColumn{
+		{ColumnName: "id", ColumnType: "integer", Ordinal: 1, IsIdentity: true},
+		{ColumnName: "seq", ColumnType: "bigint", Ordinal: 2, ColumnDefault: lo.ToPtr("nextval('t_log_seq_seq'::regclass)")},
+		{ColumnName: "status", ColumnType: "smallint", Ordinal: 4, ColumnDefault: lo.ToPtr("0")},
+	}
+	schema := buildPostgresSchema(postgresTable{TableName: "t_log"}, "audit", fields, nil, nil)
+
+	if schema.Namespace != "audit" || schema.Name != "t_log" || len(schema.Columns) != 3 {
+		t.Fatalf("表信息解析不正确: %s", schema.Json())
+	}
+	if !schema.Columns[0].IsAutoIncrement || !schema.Columns[1].IsAutoIncrement || schema.Columns[1].Default != nil {
+		t.Errorf("identity 列和 serial 列应为自增列，且不保留 nextval 默认值: %+v %+v", schema.Columns[0], schema.Columns[1])
+	}
+	if schema.Columns[2].IsAutoIncrement || schema.Columns[2].Default == nil || *schema.Columns[2].Default != "0" || schema.Columns[2].OrdinalPosition != 4 {
+		t.Errorf("status 列元数据解析不正确: %+v", schema.Columns[2])
+	}
+	if public := buildPostgresSchema(postgresTable{TableName: "t_log"}, defaultPostgresSchema, fields, nil, nil); public.Namespace != "" {
+		t.Errorf("public schema 的表不应带 schema 前缀: %s", public.Namespace)
+	}
+}
@@ -0,0 +1,282 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/glebarez/sqlite"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// SqliteParser SQLite 解析器，直接读取本地 .db 文件中的表结构
+// 使用纯 Go 实现的驱动，不依赖 cgo，适合在 CI 中使用
+type SqliteParser struct {
+	db        *gorm.DB
+	configger *config.Configger
+}
+
+// NewSqliteParser 创建 SQLite 解析器
+// 从配置中读取 sqlite_file_path，以只读方式打开数据库文件
+func NewSqliteParser(cfg *config.Configger) (*SqliteParser, error) {
+	sqliteFilePath := cfg.GenerateConfig.SqliteFilePath
+	if sqliteFilePath == "" {
+		return nil, fmt.Errorf("sqlite模式下必须配置sqlite_file_path")
+	}
+
+	// 文件不存在时 SQLite 会自动创建空库，这里提前检查避免生成空结果
+	if _, err := os.Stat(sqliteFilePath); err != nil {
+		return nil, fmt.Errorf("读取SQLite文件失败 [%s]: %w", sqliteFilePath, err)
+	}
+
+	db, err := gorm.Open(sqlite.Open(sqliteReadOnlyURI(sqliteFilePath)), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("打开SQLite文件失败 [%s]: %w", sqliteFilePath, err)
+	}
+
+	return &SqliteParser{
+		db:        db,
+		configger: cfg,
+	}, nil
+}
+
+// sqliteReadOnlyURI 构建以只读方式打开文件的 SQLite URI
+// 路径需要转义，否则文件名中的 ?、#、% 会被当作查询参数、片段或转义序列
+// 示例: /data/a?b.db -> file:/data/a%3Fb.db?mode=ro
+func sqliteReadOnlyURI(path string) string {
+	return (&url.URL{
+		Scheme:   "file",
+		Opaque:   (&url.URL{Path: filepath.ToSlash(path)}).EscapedPath(),
+		RawQuery: "mode=ro",
+	}).String()
+}
+
+// sqliteTable sqlite_master 中的表信息
+type sqliteTable struct {
+	Name string `gorm:"column:name"` // 表名
+	Sql  string `gorm:"column:sql"`  // 建表语句
+}
+
+// sqliteField PRAGMA table_info 的返回结果
+type sqliteField struct {
+	Cid       int     `gorm:"column:cid"`        // 列序号（从0开始）
+	Name      string  `gorm:"column:name"`       // 列名
+	Type      string  `gorm:"column:type"`       // 声明的列类型
+	NotNull   int     `gorm:"column:notnull"`    // 是否 NOT NULL（1=是）
+	DfltValue *string `gorm:"column:dflt_value"` // 默认值（可能为null）
+	Pk        int     `gorm:"column:pk"`         // 在主键中的位置（0表示不是主键，从1开始）
+}
+
+// sqliteIndex PRAGMA index_list 的返回结果
+type sqliteIndex struct {
+	Seq     int    `gorm:"column:seq"`     // 序号
+	Name    string `gorm:"column:name"`    // 索引名
+	Unique  int    `gorm:"column:unique"`  // 是否唯一索引（1=是）
+	Origin  string `gorm:"column:origin"`  // 来源（c=CREATE INDEX，u=UNIQUE约束，pk=主键）
+	Partial int    `gorm:"column:partial"` // 是否部分索引
+}
+
+// sqliteIndexColumn PRAGMA index_xinfo 的返回结果
+type sqliteIndexColumn struct {
+	SeqNo int     `gorm:"column:seqno"` // 列在索引中的序号（从0开始）
+	Cid   int     `gorm:"column:cid"`   // 列在表中的序号，表达式索引为 -2
+	Name  *string `gorm:"column:name"`  // 列名（表达式索引为null）
+	Desc  int     `gorm:"column:desc"`  // 是否降序（1=是）
+	Key   int     `gorm:"column:key"`   // 是否为索引键（0表示附加在索引末尾的 rowid）
+}
+
+// sqliteForeignKey PRAGMA foreign_key_list 的返回结果，每个外键列一行
+type sqliteForeignKey struct {
+	Id       int     `gorm:"column:id"`        // 外键序号，同一外键的各列序号相同
+	Seq      int     `gorm:"column:seq"`       // 列在外键中的序号（从0开始）
+	Table    string  `gorm:"column:table"`     // 被引用的表名
+	From     string  `gorm:"column:from"`      // 外键列名
+	To       *string `gorm:"column:to"`        // 被引用的列名（省略时为null，表示引用主键）
+	OnUpdate string  `gorm:"column:on_update"` // 更新时的动作
+	OnDelete string  `gorm:"column:on_delete"` // 删除时的动作
+}
+
+// Parse 解析 SQLite 文件中的所有表
+func (p *SqliteParser) Parse() (schemas []model.Schema, err error) {
+	var tables []sqliteTable
+	err = p.db.Raw("select name, sql from sqlite_master where type = 'table' and name not like 'sqlite_%' order by name").
+		Scan(&tables).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询数据库表失败: %w", err)
+	}
+
+	for _, table := range tables {
+		var schema model.Schema
+		schema, err = p.parseTable(table)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+	return applyAnnotations(schemas), nil
+}
+
+// parseTable 通过 PRAGMA 读取单个表的列和索引
+func (p *SqliteParser) parseTable(table sqliteTable) (schema model.Schema, err error) {
+	schema.Name = table.Name
+
+	var fields []sqliteField
+	if err = p.db.Raw(fmt.Sprintf("PRAGMA table_info(%s)", quoteSqliteIdent(table.Name))).Scan(&fields).Error; err != nil {
+		return schema, fmt.Errorf("查询表字段失败 [%s]: %w", table.Name, err)
+	}
+
+	// 主键列按 pk 的位置排序，保证联合主键的列顺序与定义一致
+	pkFields := lo.Filter(fields, func(field sqliteField, _ int) bool {
+		return field.Pk > 0
+	})
+	sort.Slice(pkFields, func(i, j int) bool {
+		return pkFields[i].Pk < pkFields[j].Pk
+	})
+
+	// 单列 INTEGER 主键是 rowid 的别名，由 SQLite 自动分配
+	rowidAlias := len(pkFields) == 1 && strings.EqualFold(pkFields[0].Type, "integer")
+
+	columnIndexMap := make(map[string]int)
+	for _, field := range fields {
+		column := model.Column{
+			ColumnName:      field.Name,
+			Type:            field.Type,
+			Default:         field.DfltValue,
+			IsNullable:      field.NotNull == 0 && field.Pk == 0,
+			IsPrimaryKey:    field.Pk > 0,
+			IsIndexed:       field.Pk > 0,
+			IsAutoIncrement: field.Pk > 0 && rowidAlias,
+			OrdinalPosition: field.Cid + 1,
+		}
+		schema.Columns = append(schema.Columns, column)
+		columnIndexMap[column.ColumnName] = len(schema.Columns) - 1
+	}
+
+	if len(pkFields) > 0 {
+		schema.PrimaryKey = model.Index{
+			IndexName: "PRIMARY",
+			IsUnique:  true,
+			Columns: lo.Map(pkFields, func(field sqliteField, _ int) model.Column {
+				return schema.Columns[columnIndexMap[field.Name]]
+			}),
+			Parts: lo.Map(pkFields, func(field sqliteField, _ int) model.IndexPart {
+				return model.IndexPart{ColumnName: field.Name, Seq: field.Pk, Direction: "ASC"}
+			}),
+		}
+	}
+
+	var indexes []sqliteIndex
+	if err = p.db.Raw(fmt.Sprintf("PRAGMA index_list(%s)", quoteSqliteIdent(table.Name))).Scan(&indexes).Error; err != nil {
+		return schema, fmt.Errorf("查询表索引失败 [%s]: %w", table.Name, err)
+	}
+
+	// index_list 的顺序与索引创建顺序相反，这里按索引名排序保证结果稳定
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i].Name < indexes[j].Name
+	})
+
+	for _, index := range indexes {
+		// 主键已经通过 table_info 处理
+		if index.Origin == "pk" {
+			continue
+		}
+
+		var indexColumns []sqliteIndexColumn
+		if err = p.db.Raw(fmt.Sprintf("PRAGMA index_xinfo(%s)", quoteSqliteIdent(index.Name))).Scan(&indexColumns).Error; err != nil {
+			return schema, fmt.Errorf("查询索引字段失败 [%s.%s]: %w", table.Name, index.Name, err)
+		}
+		sort.Slice(indexColumns, func(i, j int) bool {
+			return indexColumns[i].SeqNo < indexColumns[j].SeqNo
+		})
+
+		sqliteIndex := model.Index{IndexName: index.Name, IsUnique: index.Unique == 1}
+		for _, indexColumn := range indexColumns {
+			// 跳过 rowid 和表达式索引的列
+			if indexColumn.Key == 0 || indexColumn.Name == nil {
+				continue
+			}
+			colIdx, exists := columnIndexMap[*indexColumn.Name]
+			if !exists {
+				continue
+			}
+			schema.Columns[colIdx].IsIndexed = true
+			if index.Unique == 1 {
+				schema.Columns[colIdx].IsUnique = true
+			}
+			sqliteIndex.Columns = append(sqliteIndex.Columns, schema.Columns[colIdx])
+			sqliteIndex.Parts = append(sqliteIndex.Parts, model.IndexPart{
+				ColumnName: *indexColumn.Name,
+				Seq:        indexColumn.SeqNo + 1,
+				Direction:  lo.Ternary(indexColumn.Desc == 1, "DESC", "ASC"),
+			})
+		}
+		if len(sqliteIndex.Columns) == 0 {
+			continue
+		}
+
+		if sqliteIndex.IsUnique {
+			schema.UniqueIndex = append(schema.UniqueIndex, sqliteIndex)
+		} else {
+			schema.Indexes = append(schema.Indexes, sqliteIndex)
+		}
+	}
+
+	schema.ForeignKeys, err = p.parseForeignKeys(table.Name)
+	if err != nil {
+		return schema, err
+	}
+
+	return schema, nil
+}
+
+// parseForeignKeys 通过 PRAGMA foreign_key_list 读取表的外键
+// SQLite 的外键没有名称，统一命名为 <表名>_fk_<序号>；省略引用列时引用的是被引用表的主键
+func (p *SqliteParser) parseForeignKeys(tableName string) ([]model.ForeignKey, error) {
+	var foreignKeys []sqliteForeignKey
+	if err := p.db.Raw(fmt.Sprintf("PRAGMA foreign_key_list(%s)", quoteSqliteIdent(tableName))).Scan(&foreignKeys).Error; err != nil {
+		return nil, fmt.Errorf("查询表外键失败 [%s]: %w", tableName, err)
+	}
+
+	var rows []foreignKeyRow
+	for _, foreignKey := range foreignKeys {
+		referencedColumn := lo.FromPtr(foreignKey.To)
+		if foreignKey.To == nil {
+			var referencedFields []sqliteField
+			if err := p.db.Raw(fmt.Sprintf("PRAGMA table_info(%s)", quoteSqliteIdent(foreignKey.Table))).Scan(&referencedFields).Error; err != nil {
+				return nil, fmt.Errorf("查询表字段失败 [%s]: %w", foreignKey.Table, err)
+			}
+			if pkField, found := lo.Find(referencedFields, func(field sqliteField) bool {
+				return field.Pk == foreignKey.Seq+1
+			}); found {
+				referencedColumn = pkField.Name
+			}
+		}
+		rows = append(rows, foreignKeyRow{
+			TableName:            tableName,
+			ConstraintName:       fmt.Sprintf("%s_fk_%d", tableName, foreignKey.Id),
+			ColumnName:           foreignKey.From,
+			OrdinalPosition:      foreignKey.Seq + 1,
+			ReferencedTableName:  foreignKey.Table,
+			ReferencedColumnName: referencedColumn,
+			DeleteRule:           foreignKey.OnDelete,
+			UpdateRule:           foreignKey.OnUpdate,
+		})
+	}
+	return buildForeignKeys(rows), nil
+}
+
+// FilterTables 根据配置文件过滤表，规则见 filterTables
+func (p *SqliteParser) FilterTables(schemas []model.Schema) (filtered []model.Schema) {
+	return filterTables(p.configger.GenerateConfig, schemas)
+}
+
+// quoteSqliteIdent 为 PRAGMA 语句中的标识符加引号
+func quoteSqliteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// newSqliteTestFile 在临时目录中创建一个 SQLite 文件并执行建表语句
+func newSqliteTestFile(t *testing.T, statements ...string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("创建SQLite文件失败: %v", err)
+	}
+	for _, statement := range statements {
+		if err = db.Exec(statement).Error; err != nil {
+			t.Fatalf("执行建表语句失败: %v", err)
+		}
+	}
+	sqlDB, _ := db.DB()
+	_ = sqlDB.Close()
+	return path
+}
+
+func TestSqliteParserParse(t *testing.T) {
+	path := newSqliteTestFile(t,
+		`create table t_user (
+			id integer primary key autoincrement,
+			user_name varchar(64) not null,
+			email text,
+			age integer default 0,
+			created_at datetime not null
+		)`,
+		`create unique index uk_user_name on t_user (user_name)`,
+		`create index idx_email_age on t_user (email, age)`,
+		`create table t_user_role (
+			user_id integer not null,
+			role_id integer not null,
+			primary key (user_id, role_id),
+			foreign key (user_id) references t_user on delete cascade
+		)`,
+	)
+
+	cfg, err := config.NewBuilder().SqliteMode(path).AllTables().Build()
+	if err != nil {
+		t.Fatalf("构建配置失败: %v", err)
+	}
+	sqliteParser, err := NewSqliteParser(cfg)
+	if err != nil {
+		t.Fatalf("创建解析器失败: %v", err)
+	}
+
+	schemas, err := sqliteParser.Parse()
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(schemas) != 2 {
+		t.Fatalf("期望解析出 2 个表，实际为 %d", len(schemas))
+	}
+
+	user := schemas[0]
+	if user.Name != "t_user" || len(user.Columns) != 5 {
+		t.Fatalf("t_user 解析结果不正确: %s", user.Json())
+	}
+	id := user.Columns[0]
+	if !id.IsPrimaryKey || !id.IsAutoIncrement || id.IsNullable {
+		t.Errorf("id 列应为非空自增主键: %+v", id)
+	}
+	if user.Columns[1].IsNullable || !user.Columns[1].IsUnique {
+		t.Errorf("user_name 列应为非空唯一列: %+v", user.Columns[1])
+	}
+	if !user.Columns[2].IsNullable || !user.Columns[2].IsIndexed {
+		t.Errorf("email 列应为可空索引列: %+v", user.Columns[2])
+	}
+	if user.Columns[3].Default == nil || *user.Columns[3].Default != "0" {
+		t.Errorf("age 列默认值应为 0: %+v", user.Columns[3])
+	}
+	if user.PrimaryKey.IndexName != "PRIMARY" || len(user.PrimaryKey.Columns) != 1 {
+		t.Errorf("t_user 主键解析不正确: %+v", user.PrimaryKey)
+	}
+	if len(user.UniqueIndex) != 1 || user.UniqueIndex[0].IndexName != "uk_user_name" {
+		t.Errorf("t_user 唯一索引解析不正确: %+v", user.UniqueIndex)
+	}
+	if len(user.Indexes) != 1 || len(user.Indexes[0].Columns) != 2 || user.Indexes[0].Columns[1].ColumnName != "age" {
+		t.Errorf("t_user 普通索引解析不正确: %+v", user.Indexes)
+	}
+
+	userRole := schemas[1]
+	if len(userRole.PrimaryKey.Columns) != 2 {
+		t.Fatalf("t_user_role 应为联合主键: %+v", userRole.PrimaryKey)
+	}
+	if userRole.Columns[0].IsAutoIncrement {
+		t.Errorf("联合主键的列不应为自增列: %+v", userRole.Columns[0])
+	}
+	if len(userRole.ForeignKeys) != 1 {
+		t.Fatalf("t_user_role 外键解析不正确: %+v", userRole.ForeignKeys)
+	}
+	if foreignKey := userRole.ForeignKeys[0]; foreignKey.ReferencedTable != "t_user" || foreignKey.ReferencedColumns[0] != "id" || foreignKey.OnDelete != "CASCADE" {
+		t.Errorf("省略引用列的外键应引用主键: %+v", foreignKey)
+	}
+}
+
+func TestNewSqliteParserMissingFile(t *testing.T) {
+	cfg, err := config.NewBuilder().
+		SqliteMode(filepath.Join(t.TempDir(), "missing.db")).
+		AllTables().
+		Build()
+	if err != nil {
+		t.Fatalf("构建配置失败: %v", err)
+	}
+	if _, err = NewSqliteParser(cfg); err == nil {
+		t.Fatal("文件不存在时应返回错误")
+	}
+}
+
+func TestNewSqliteParserSpecialFileName(t *testing.T) {
+	path := filepath.Join(filepath.Dir(newSqliteTestFile(t, `create table t_user (id integer primary key)`)), "test?v=1#100%.db")
+	if err := os.Rename(filepath.Join(filepath.Dir(path), "test.db"), path); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.NewBuilder().SqliteMode(path).AllTables().Build()
+	if err != nil {
+		t.Fatalf("构建配置失败: %v", err)
+	}
+
+	sqliteParser, err := NewSqliteParser(cfg)
+	if err != nil {
+		t.Fatalf("文件名包含 ?、#、%% 时应能打开: %v", err)
+	}
+	schemas, err := sqliteParser.Parse()
+	if err != nil || len(schemas) != 1 || schemas[0].Name != "t_user" {
+		t.Fatalf("应读取到文件中的表: %+v, %v", schemas, err)
+	}
+	if err = sqliteParser.db.Exec(`create table t_other (id integer)`).Error; err == nil {
+		t.Error("应以只读方式打开文件")
+	}
+}
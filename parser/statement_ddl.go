@@ -0,0 +1,518 @@
+package parser
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/LingoJack/model_infrax/model"
+	"github.com/pingcap/tidb/pkg/parser/ast"
+	"github.com/samber/lo"
+)
+
+// applyCreateTable 执行 CREATE TABLE，支持 IF NOT EXISTS 和 CREATE TABLE ... LIKE
+func applyCreateTable(schemas []model.Schema, stmt *ast.CreateTableStmt) ([]model.Schema, error) {
+	tableName := stmt.Table.Name.O
+	if findSchema(schemas, tableName) >= 0 {
+		if stmt.IfNotExists {
+			log.Printf("⚠️ 表已存在，跳过 CREATE TABLE IF NOT EXISTS: %s", tableName)
+			return schemas, nil
+		}
+		return schemas, fmt.Errorf("表已存在: %s", tableName)
+	}
+
+	if stmt.ReferTable != nil {
+		referIdx := findSchema(schemas, stmt.ReferTable.Name.O)
+		if referIdx < 0 {
+			return schemas, fmt.Errorf("CREATE TABLE ... LIKE 引用的表不存在: %s", stmt.ReferTable.Name.O)
+		}
+		schema := cloneSchema(schemas[referIdx])
+		schema.Name = tableName
+		// CREATE TABLE ... LIKE 不会复制外键
+		schema.ForeignKeys = nil
+		log.Printf("✅ 成功复制表结构: %s -> %s", stmt.ReferTable.Name.O, tableName)
+		return append(schemas, schema), nil
+	}
+
+	return append(schemas, parseCreateTable(stmt)), nil
+}
+
+// applyAlterTable 将 ALTER TABLE 的各项变更回放到表结构上
+func applyAlterTable(schemas []model.Schema, stmt *ast.AlterTableStmt) ([]model.Schema, error) {
+	tableName := stmt.Table.Name.O
+	schemaIdx := findSchema(schemas, tableName)
+	if schemaIdx < 0 {
+		return schemas, fmt.Errorf("ALTER TABLE 的表不存在: %s", tableName)
+	}
+
+	schema := &schemas[schemaIdx]
+	for _, spec := range stmt.Specs {
+		if spec.Tp == ast.AlterTableRenameTable && findSchema(schemas, spec.NewTable.Name.O) >= 0 {
+			return schemas, fmt.Errorf("ALTER TABLE %s 失败: 表已存在: %s", tableName, spec.NewTable.Name.O)
+		}
+		if err := alterSchema(schema, spec); err != nil {
+			return schemas, fmt.Errorf("ALTER TABLE %s 失败: %w", tableName, err)
+		}
+	}
+	syncIndexColumns(schema)
+	if schema.Name != tableName {
+		renameReferencedTable(schemas, tableName, schema.Name)
+	}
+
+	log.Printf("✅ 成功回放 ALTER TABLE: %s, 列数: %d, 索引数: %d", schema.Name, len(schema.Columns), len(schema.Indexes))
+	return schemas, nil
+}
+
+// alterSchema 执行单个 ALTER TABLE 子句
+// 与索引相关的列属性和索引中的列副本统一由 syncIndexColumns 刷新
+func alterSchema(schema *model.Schema, spec *ast.AlterTableSpec) error {
+	switch spec.Tp {
+	case ast.AlterTableOption:
+		for _, option := range spec.Options {
+			if option.Tp == ast.TableOptionComment {
+				schema.Comment = option.StrValue
+			}
+		}
+
+	case ast.AlterTableAddColumns:
+		for _, col := range spec.NewColumns {
+			if findColumn(*schema, col.Name.Name.O) >= 0 {
+				if spec.IfNotExists {
+					continue
+				}
+				return fmt.Errorf("列已存在: %s", col.Name.Name.O)
+			}
+			// FIRST / AFTER 只在添加单列时有效
+			position := spec.Position
+			if len(spec.NewColumns) > 1 {
+				position = nil
+			}
+			insertColumn(schema, parseColumnDef(col), len(schema.Columns), position)
+		}
+		// ADD COLUMN (a int, b int, INDEX idx_a (a)) 中的索引
+		for _, constraint := range spec.NewConstraints {
+			addConstraint(schema, constraint)
+		}
+
+	case ast.AlterTableAddConstraint:
+		addConstraint(schema, spec.Constraint)
+
+	case ast.AlterTableDropColumn:
+		colIdx := findColumn(*schema, spec.OldColumnName.Name.O)
+		if colIdx < 0 {
+			if spec.IfExists {
+				return nil
+			}
+			return fmt.Errorf("列不存在: %s", spec.OldColumnName.Name.O)
+		}
+		// 索引中引用该列的部分在 syncIndexColumns 中移除
+		schema.Columns = append(schema.Columns[:colIdx], schema.Columns[colIdx+1:]...)
+
+	case ast.AlterTableModifyColumn:
+		return replaceColumn(schema, spec.NewColumns[0].Name.Name.O, spec.NewColumns[0], spec.Position)
+
+	case ast.AlterTableChangeColumn:
+		return replaceColumn(schema, spec.OldColumnName.Name.O, spec.NewColumns[0], spec.Position)
+
+	case ast.AlterTableRenameColumn:
+		colIdx := findColumn(*schema, spec.OldColumnName.Name.O)
+		if colIdx < 0 {
+			return fmt.Errorf("列不存在: %s", spec.OldColumnName.Name.O)
+		}
+		renameIndexColumn(schema, schema.Columns[colIdx].ColumnName, spec.NewColumnName.Name.O)
+		schema.Columns[colIdx].ColumnName = spec.NewColumnName.Name.O
+
+	case ast.AlterTableAlterColumn:
+		col := spec.NewColumns[0]
+		colIdx := findColumn(*schema, col.Name.Name.O)
+		if colIdx < 0 {
+			return fmt.Errorf("列不存在: %s", col.Name.Name.O)
+		}
+		// ALTER COLUMN ... SET DEFAULT 带有一个默认值选项，DROP DEFAULT 没有选项
+		schema.Columns[colIdx].Default = nil
+		if len(col.Options) > 0 {
+			schema.Columns[colIdx].Default = parseDefaultValue(col.Options[0].Expr)
+		}
+
+	case ast.AlterTableDropPrimaryKey:
+		if !dropIndex(schema, "PRIMARY") {
+			return fmt.Errorf("主键不存在")
+		}
+
+	case ast.AlterTableDropIndex:
+		if !dropIndex(schema, spec.Name) && !spec.IfExists {
+			return fmt.Errorf("索引不存在: %s", spec.Name)
+		}
+
+	case ast.AlterTableDropForeignKey:
+		// 外键自动创建的索引不会随外键一起删除
+		_, fkIdx, found := lo.FindIndexOf(schema.ForeignKeys, func(foreignKey model.ForeignKey) bool {
+			return strings.EqualFold(foreignKey.Name, spec.Name)
+		})
+		if !found {
+			if spec.IfExists {
+				return nil
+			}
+			return fmt.Errorf("外键不存在: %s", spec.Name)
+		}
+		schema.ForeignKeys = append(schema.ForeignKeys[:fkIdx], schema.ForeignKeys[fkIdx+1:]...)
+
+	case ast.AlterTableRenameIndex:
+		if !renameIndex(schema, spec.FromKey.O, spec.ToKey.O) {
+			return fmt.Errorf("索引不存在: %s", spec.FromKey.O)
+		}
+
+	case ast.AlterTableRenameTable:
+		schema.Name = spec.NewTable.Name.O
+
+	default:
+		// ENGINE、分区、锁等子句不影响生成的代码
+		log.Printf("⚠️ 跳过不影响表结构的 ALTER TABLE 子句: %s, 类型: %d", schema.Name, spec.Tp)
+	}
+	return nil
+}
+
+// applyCreateIndex 执行 CREATE [UNIQUE] INDEX
+func applyCreateIndex(schemas []model.Schema, stmt *ast.CreateIndexStmt) ([]model.Schema, error) {
+	schemaIdx := findSchema(schemas, stmt.Table.Name.O)
+	if schemaIdx < 0 {
+		return schemas, fmt.Errorf("CREATE INDEX 的表不存在: %s", stmt.Table.Name.O)
+	}
+
+	schema := &schemas[schemaIdx]
+	if hasIndex(*schema, stmt.IndexName) {
+		if stmt.IfNotExists {
+			return schemas, nil
+		}
+		return schemas, fmt.Errorf("索引已存在: %s.%s", schema.Name, stmt.IndexName)
+	}
+
+	constraint := &ast.Constraint{
+		Tp:     ast.ConstraintIndex,
+		Name:   stmt.IndexName,
+		Keys:   stmt.IndexPartSpecifications,
+		Option: stmt.IndexOption,
+	}
+	if stmt.KeyType == ast.IndexKeyTypeUnique {
+		constraint.Tp = ast.ConstraintUniqIndex
+	}
+	addConstraint(schema, constraint)
+	syncIndexColumns(schema)
+
+	log.Printf("✅ 成功回放 CREATE INDEX: %s.%s", schema.Name, stmt.IndexName)
+	return schemas, nil
+}
+
+// applyDropIndex 执行 DROP INDEX ... ON ...
+func applyDropIndex(schemas []model.Schema, stmt *ast.DropIndexStmt) ([]model.Schema, error) {
+	schemaIdx := findSchema(schemas, stmt.Table.Name.O)
+	if schemaIdx < 0 {
+		return schemas, fmt.Errorf("DROP INDEX 的表不存在: %s", stmt.Table.Name.O)
+	}
+
+	schema := &schemas[schemaIdx]
+	if !dropIndex(schema, stmt.IndexName) && !stmt.IfExists {
+		return schemas, fmt.Errorf("索引不存在: %s.%s", schema.Name, stmt.IndexName)
+	}
+	syncIndexColumns(schema)
+	return schemas, nil
+}
+
+// applyDropTable 执行 DROP TABLE，删除视图的语句直接跳过
+func applyDropTable(schemas []model.Schema, stmt *ast.DropTableStmt) ([]model.Schema, error) {
+	if stmt.IsView {
+		return schemas, nil
+	}
+
+	for _, table := range stmt.Tables {
+		schemaIdx := findSchema(schemas, table.Name.O)
+		if schemaIdx < 0 {
+			if stmt.IfExists {
+				continue
+			}
+			return schemas, fmt.Errorf("DROP TABLE 的表不存在: %s", table.Name.O)
+		}
+		schemas = append(schemas[:schemaIdx], schemas[schemaIdx+1:]...)
+		log.Printf("✅ 成功回放 DROP TABLE: %s", table.Name.O)
+	}
+	return schemas, nil
+}
+
+// applyRenameTable 执行 RENAME TABLE a TO b, c TO d，按顺序依次重命名
+func applyRenameTable(schemas []model.Schema, stmt *ast.RenameTableStmt) ([]model.Schema, error) {
+	for _, tableToTable := range stmt.TableToTables {
+		oldName, newName := tableToTable.OldTable.Name.O, tableToTable.NewTable.Name.O
+		schemaIdx := findSchema(schemas, oldName)
+		if schemaIdx < 0 {
+			return schemas, fmt.Errorf("RENAME TABLE 的表不存在: %s", oldName)
+		}
+		if findSchema(schemas, newName) >= 0 {
+			return schemas, fmt.Errorf("RENAME TABLE 的目标表已存在: %s", newName)
+		}
+		schemas[schemaIdx].Name = newName
+		renameReferencedTable(schemas, oldName, newName)
+		log.Printf("✅ 成功回放 RENAME TABLE: %s -> %s", oldName, newName)
+	}
+	return schemas, nil
+}
+
+// findSchema 按表名查找表结构的下标，不存在时返回 -1
+func findSchema(schemas []model.Schema, tableName string) int {
+	_, schemaIdx, _ := lo.FindIndexOf(schemas, func(schema model.Schema) bool {
+		return strings.EqualFold(schema.Name, tableName)
+	})
+	return schemaIdx
+}
+
+// findColumn 按列名查找列的下标（MySQL 列名不区分大小写），不存在时返回 -1
+func findColumn(schema model.Schema, columnName string) int {
+	_, colIdx, _ := lo.FindIndexOf(schema.Columns, func(column model.Column) bool {
+		return strings.EqualFold(column.ColumnName, columnName)
+	})
+	return colIdx
+}
+
+// hasIndex 判断表上是否已存在同名索引
+func hasIndex(schema model.Schema, indexName string) bool {
+	matchName := func(index model.Index) bool {
+		return strings.EqualFold(index.IndexName, indexName)
+	}
+	return lo.ContainsBy(schema.UniqueIndex, matchName) || lo.ContainsBy(schema.Indexes, matchName)
+}
+
+// hasIndexPrefix 判断表上是否存在以 columns 为最左前缀的索引（含主键），外键可以直接使用这样的索引
+func hasIndexPrefix(schema model.Schema, columns []string) bool {
+	isPrefix := func(index model.Index) bool {
+		if len(index.Columns) < len(columns) {
+			return false
+		}
+		for i, columnName := range columns {
+			if !strings.EqualFold(index.Columns[i].ColumnName, columnName) {
+				return false
+			}
+		}
+		return true
+	}
+	return isPrefix(schema.PrimaryKey) || lo.ContainsBy(schema.UniqueIndex, isPrefix) || lo.ContainsBy(schema.Indexes, isPrefix)
+}
+
+// renameReferencedTable 表重命名后，同步修改其他表外键中引用的表名
+func renameReferencedTable(schemas []model.Schema, oldName, newName string) {
+	for i := range schemas {
+		for j := range schemas[i].ForeignKeys {
+			if strings.EqualFold(schemas[i].ForeignKeys[j].ReferencedTable, oldName) {
+				schemas[i].ForeignKeys[j].ReferencedTable = newName
+			}
+		}
+	}
+}
+
+// insertColumn 按 FIRST / AFTER 指定的位置插入列，未指定位置时插入到 defaultIdx
+func insertColumn(schema *model.Schema, column model.Column, defaultIdx int, position *ast.ColumnPosition) {
+	colIdx := defaultIdx
+	if position != nil {
+		switch position.Tp {
+		case ast.ColumnPositionFirst:
+			colIdx = 0
+		case ast.ColumnPositionAfter:
+			if afterIdx := findColumn(*schema, position.RelativeColumn.Name.O); afterIdx >= 0 {
+				colIdx = afterIdx + 1
+			}
+		}
+	}
+	schema.Columns = append(schema.Columns[:colIdx], append([]model.Column{column}, schema.Columns[colIdx:]...)...)
+}
+
+// replaceColumn 执行 MODIFY / CHANGE COLUMN，用新的列定义替换旧列
+// MySQL 修改列定义不会删除列上的索引，因此保留旧列的主键、唯一、索引标记
+func replaceColumn(schema *model.Schema, oldName string, col *ast.ColumnDef, position *ast.ColumnPosition) error {
+	colIdx := findColumn(*schema, oldName)
+	if colIdx < 0 {
+		return fmt.Errorf("列不存在: %s", oldName)
+	}
+
+	oldColumn := schema.Columns[colIdx]
+	column := parseColumnDef(col)
+	column.IsPrimaryKey = column.IsPrimaryKey || oldColumn.IsPrimaryKey
+	column.IsUnique = column.IsUnique || oldColumn.IsUnique
+	column.IsIndexed = column.IsIndexed || oldColumn.IsIndexed
+
+	renameIndexColumn(schema, oldColumn.ColumnName, column.ColumnName)
+	schema.Columns = append(schema.Columns[:colIdx], schema.Columns[colIdx+1:]...)
+	insertColumn(schema, column, colIdx, position)
+	return nil
+}
+
+// renameIndexColumn 同步修改索引中引用的列名
+func renameIndexColumn(schema *model.Schema, oldName, newName string) {
+	rename := func(index *model.Index) {
+		for i := range index.Columns {
+			if strings.EqualFold(index.Columns[i].ColumnName, oldName) {
+				index.Columns[i].ColumnName = newName
+			}
+		}
+		for i := range index.Parts {
+			if strings.EqualFold(index.Parts[i].ColumnName, oldName) {
+				index.Parts[i].ColumnName = newName
+			}
+		}
+	}
+	for i := range schema.ForeignKeys {
+		for j, columnName := range schema.ForeignKeys[i].Columns {
+			if strings.EqualFold(columnName, oldName) {
+				schema.ForeignKeys[i].Columns[j] = newName
+			}
+		}
+	}
+	rename(&schema.PrimaryKey)
+	for i := range schema.UniqueIndex {
+		rename(&schema.UniqueIndex[i])
+	}
+	for i := range schema.Indexes {
+		rename(&schema.Indexes[i])
+	}
+}
+
+// dropIndex 删除索引并清除其中列的索引标记，索引不存在时返回 false
+// 仍被其他索引引用的列会在 syncIndexColumns 中重新标记
+func dropIndex(schema *model.Schema, indexName string) bool {
+	clearFlags := func(index model.Index, clear func(column *model.Column)) {
+		for _, indexColumn := range index.Columns {
+			if colIdx := findColumn(*schema, indexColumn.ColumnName); colIdx >= 0 {
+				clear(&schema.Columns[colIdx])
+			}
+		}
+	}
+
+	if strings.EqualFold(indexName, "PRIMARY") {
+		// 列定义中直接声明的 PRIMARY KEY 不会生成主键索引，这里同时清除列上的主键标记
+		exists := len(schema.PrimaryKey.Columns) > 0
+		for i := range schema.Columns {
+			if schema.Columns[i].IsPrimaryKey {
+				exists = true
+				schema.Columns[i].IsPrimaryKey = false
+				schema.Columns[i].IsIndexed = false
+			}
+		}
+		schema.PrimaryKey = model.Index{}
+		return exists
+	}
+
+	matchName := func(index model.Index) bool {
+		return strings.EqualFold(index.IndexName, indexName)
+	}
+	if index, indexIdx, found := lo.FindIndexOf(schema.UniqueIndex, matchName); found {
+		clearFlags(index, func(column *model.Column) {
+			column.IsUnique = false
+			column.IsIndexed = false
+		})
+		schema.UniqueIndex = append(schema.UniqueIndex[:indexIdx], schema.UniqueIndex[indexIdx+1:]...)
+		return true
+	}
+	if index, indexIdx, found := lo.FindIndexOf(schema.Indexes, matchName); found {
+		clearFlags(index, func(column *model.Column) {
+			column.IsIndexed = false
+		})
+		schema.Indexes = append(schema.Indexes[:indexIdx], schema.Indexes[indexIdx+1:]...)
+		return true
+	}
+	return false
+}
+
+// renameIndex 重命名索引，索引不存在时返回 false
+func renameIndex(schema *model.Schema, fromName, toName string) bool {
+	for _, indexes := range [][]model.Index{schema.UniqueIndex, schema.Indexes} {
+		for i := range indexes {
+			if strings.EqualFold(indexes[i].IndexName, fromName) {
+				indexes[i].IndexName = toName
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// syncIndexColumns 在回放变更后刷新表结构:
+//   - 重新计算列的位置
+//   - 根据剩余的索引重新标记列的主键、唯一、索引属性
+//   - 索引中保存的是列的副本，这里替换为最新的列定义，已删除的列从索引中移除，没有列的索引一并删除
+func syncIndexColumns(schema *model.Schema) {
+	for i := range schema.Columns {
+		schema.Columns[i].OrdinalPosition = i + 1
+	}
+
+	markFlags := func(index model.Index, mark func(column *model.Column)) {
+		for _, indexColumn := range index.Columns {
+			if colIdx := findColumn(*schema, indexColumn.ColumnName); colIdx >= 0 {
+				mark(&schema.Columns[colIdx])
+				schema.Columns[colIdx].IsIndexed = true
+			}
+		}
+	}
+	markFlags(schema.PrimaryKey, func(column *model.Column) {
+		column.IsPrimaryKey = true
+	})
+	for _, index := range schema.UniqueIndex {
+		markFlags(index, func(column *model.Column) {
+			column.IsUnique = true
+		})
+	}
+	for _, index := range schema.Indexes {
+		markFlags(index, func(column *model.Column) {})
+	}
+
+	refresh := func(index model.Index) model.Index {
+		var columns []model.Column
+		var parts []model.IndexPart
+		for i, indexColumn := range index.Columns {
+			colIdx := findColumn(*schema, indexColumn.ColumnName)
+			if colIdx < 0 {
+				continue
+			}
+			columns = append(columns, schema.Columns[colIdx])
+			if i < len(index.Parts) {
+				// 删除列后，剩余列在索引中的序号随之前移
+				part := index.Parts[i]
+				part.Seq = len(columns)
+				parts = append(parts, part)
+			}
+		}
+		index.Columns, index.Parts = columns, parts
+		return index
+	}
+	hasColumns := func(index model.Index, _ int) bool {
+		return len(index.Columns) > 0
+	}
+
+	schema.PrimaryKey = refresh(schema.PrimaryKey)
+	if len(schema.PrimaryKey.Columns) == 0 {
+		schema.PrimaryKey = model.Index{}
+	}
+	schema.UniqueIndex = lo.Filter(lo.Map(schema.UniqueIndex, func(index model.Index, _ int) model.Index {
+		return refresh(index)
+	}), hasColumns)
+	schema.Indexes = lo.Filter(lo.Map(schema.Indexes, func(index model.Index, _ int) model.Index {
+		return refresh(index)
+	}), hasColumns)
+
+	// 外键中的列被删除时，外键随之失效
+	schema.ForeignKeys = lo.Filter(schema.ForeignKeys, func(foreignKey model.ForeignKey, _ int) bool {
+		return lo.EveryBy(foreignKey.Columns, func(columnName string) bool {
+			return findColumn(*schema, columnName) >= 0
+		})
+	})
+}
+
+// cloneSchema 深拷贝表结构，用于 CREATE TABLE ... LIKE
+func cloneSchema(schema model.Schema) model.Schema {
+	cloneIndex := func(index model.Index, _ int) model.Index {
+		index.Columns = append([]model.Column(nil), index.Columns...)
+		index.Parts = append([]model.IndexPart(nil), index.Parts...)
+		return index
+	}
+	schema.Columns = append([]model.Column(nil), schema.Columns...)
+	schema.PrimaryKey = cloneIndex(schema.PrimaryKey, 0)
+	schema.UniqueIndex = lo.Map(schema.UniqueIndex, cloneIndex)
+	schema.Indexes = lo.Map(schema.Indexes, cloneIndex)
+	return schema
+}
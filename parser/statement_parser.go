@@ -3,25 +3,27 @@ package parser
 import (
 	"fmt"
 	"log"
-
-	"os"
+	"regexp"
 	"strings"
 
 	"github.com/LingoJack/model_infrax/config"
 	"github.com/LingoJack/model_infrax/model"
 	"github.com/pingcap/tidb/pkg/parser"
 	"github.com/pingcap/tidb/pkg/parser/ast"
+	"github.com/pingcap/tidb/pkg/parser/format"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
 	"github.com/pingcap/tidb/pkg/parser/test_driver"
 	"github.com/samber/lo"
 )
 
 type StatementParser struct {
-	configger  *config.Configger
-	statements []string
+	configger *config.Configger
+	sqlFiles  []sqlFile
 }
 
 // NewStatementParser 创建SQL语句解析器
 // 从配置文件中读取SQL文件路径，解析SQL文件内容
+// sql_file_path 可以是单个文件，也可以是迁移目录或 glob，多个文件按迁移版本号顺序执行
 func NewStatementParser(cfg *config.Configger) (*StatementParser, error) {
 	// 从配置中获取SQL文件路径
 	sqlFilePath := cfg.GenerateConfig.SqlFilePath
@@ -30,77 +32,93 @@ func NewStatementParser(cfg *config.Configger) (*StatementParser, error) {
 	}
 
 	// 读取SQL文件内容
-	byts, err := os.ReadFile(sqlFilePath)
+	sqlFiles, err := loadSQLFiles(sqlFilePath)
 	if err != nil {
-		return nil, fmt.Errorf("读取SQL文件失败 [%s]: %w", sqlFilePath, err)
+		return nil, err
 	}
 
-	// 按分号分割SQL语句
-	statements := strings.Split(string(byts), ";")
-
-	log.Printf("📄 成功加载SQL文件: %s, 共 %d 条语句", sqlFilePath, len(statements))
+	log.Printf("📄 成功加载SQL文件: %s, 共 %d 个文件", sqlFilePath, len(sqlFiles))
 
 	return &StatementParser{
-		configger:  cfg,
-		statements: statements,
+		configger: cfg,
+		sqlFiles:  sqlFiles,
 	}, nil
 }
 
+// Parse 按顺序执行SQL文件中的语句，得到所有表的最终结构
+// 除了 CREATE TABLE，还会将 ALTER TABLE、CREATE INDEX、DROP TABLE、RENAME TABLE 等变更回放到已解析的表上，
+// 因此 基础DDL + 增量迁移 的文件最终生成的是迁移后的表结构
 func (p *StatementParser) Parse() (schemas []model.Schema, err error) {
-	for _, statement := range p.statements {
-		// 跳过空语句
-		trimmed := strings.TrimSpace(statement)
-		if trimmed == "" {
-			continue
-		}
-
-		log.Printf("⌛️ parsing statement: %s", statement)
-		var schema model.Schema
-		schema, err = p.parseStatement(statement)
+	tidbParser := parser.New()
+	for _, file := range p.sqlFiles {
+		schemas, err = p.applyFile(tidbParser, schemas, file)
 		if err != nil {
-			return nil, fmt.Errorf("解析语句失败: %w", err)
+			return nil, err
 		}
-		schemas = append(schemas, schema)
 	}
-	return
+	return applyAnnotations(schemas), nil
 }
 
-func (p *StatementParser) FilterTables(schemas []model.Schema) (filtered []model.Schema) {
-	if p.configger.GenerateConfig.AllTables {
-		filtered = schemas
-		return
-	}
-	filtered = lo.Filter(schemas, func(schema model.Schema, index int) bool {
-		return lo.Contains(p.configger.GenerateConfig.TableNames, schema.Name)
-	})
-	return
-}
-
-// parseStatement 解析单个CREATE TABLE语句，提取表结构信息
-func (p *StatementParser) parseStatement(statement string) (schema model.Schema, err error) {
-	// 创建TiDB parser实例
-	tidbParser := parser.New()
+// applyFile 使用 TiDB parser 一次性解析整个文件，再按顺序执行其中的语句
+// 整个文件交给 parser 处理，COMMENT、默认值等字符串中的分号不会被错误地切分
+func (p *StatementParser) applyFile(tidbParser *parser.Parser, schemas []model.Schema, file sqlFile) ([]model.Schema, error) {
+	content := expandDelimiters(tidbParser, file)
 
-	// 解析SQL语句
-	stmtNodes, _, err := tidbParser.ParseSQL(statement)
+	stmtNodes, _, err := tidbParser.ParseSQL(content)
 	if err != nil {
-		return schema, fmt.Errorf("SQL解析失败: %w", err)
+		return schemas, fmt.Errorf("SQL解析失败 [%s]: %w", parseErrorPosition(file.Path, err), err)
 	}
 
-	// 确保至少有一个语句节点
-	if len(stmtNodes) == 0 {
-		return schema, fmt.Errorf("未找到有效的SQL语句")
+	// parser 不记录语句在原文中的偏移量，这里按语句文本在原文中依次查找
+	cursor := 0
+	for _, stmtNode := range stmtNodes {
+		text := stmtNode.Text()
+		offset := cursor
+		if idx := strings.Index(content[cursor:], text); idx >= 0 {
+			offset = cursor + idx
+			cursor = offset + len(text)
+		}
+		trivia := leadingTrivia(text)
+		position := textPosition(file.Path, content, offset+trivia)
+
+		log.Printf("⌛️ parsing statement [%s]: %s", position, strings.TrimSpace(text[trivia:]))
+		schemas, err = applyStatement(schemas, stmtNode, position)
+		if err != nil {
+			return schemas, fmt.Errorf("解析语句失败 [%s]: %w", position, err)
+		}
 	}
+	return schemas, nil
+}
 
-	// 获取第一个语句节点
-	stmtNode := stmtNodes[0]
+// FilterTables 根据配置文件过滤表，规则见 filterTables
+func (p *StatementParser) FilterTables(schemas []model.Schema) (filtered []model.Schema) {
+	return filterTables(p.configger.GenerateConfig, schemas)
+}
 
-	// 类型断言为CREATE TABLE语句
-	createTableStmt, ok := stmtNode.(*ast.CreateTableStmt)
-	if !ok {
-		return schema, fmt.Errorf("不是CREATE TABLE语句")
+// applyStatement 将单条语句作用到已解析的表结构上，不影响表结构的语句跳过并给出警告
+func applyStatement(schemas []model.Schema, stmtNode ast.StmtNode, position string) (_ []model.Schema, err error) {
+	switch stmt := stmtNode.(type) {
+	case *ast.CreateTableStmt:
+		schemas, err = applyCreateTable(schemas, stmt)
+	case *ast.AlterTableStmt:
+		schemas, err = applyAlterTable(schemas, stmt)
+	case *ast.CreateIndexStmt:
+		schemas, err = applyCreateIndex(schemas, stmt)
+	case *ast.DropIndexStmt:
+		schemas, err = applyDropIndex(schemas, stmt)
+	case *ast.DropTableStmt:
+		schemas, err = applyDropTable(schemas, stmt)
+	case *ast.RenameTableStmt:
+		schemas, err = applyRenameTable(schemas, stmt)
+	default:
+		// SET、USE、INSERT 等语句不影响表结构，直接跳过
+		log.Printf("⚠️ 跳过不影响表结构的语句 [%s]: %T", position, stmtNode)
 	}
+	return schemas, err
+}
 
+// parseCreateTable 解析单个CREATE TABLE语句，提取表结构信息
+func parseCreateTable(createTableStmt *ast.CreateTableStmt) (schema model.Schema) {
 	// 提取表名
 	schema.Name = createTableStmt.Table.Name.O
 
@@ -112,154 +130,347 @@ func (p *StatementParser) parseStatement(statement string) (schema model.Schema,
 		}
 	}
 
-	// 用于存储列名到列索引的映射，方便后续索引处理
-	// 注意：不能使用指针映射，因为 slice 扩容会导致指针失效
-	columnIndexMap := make(map[string]int)
-
 	// 提取列信息
-	for _, col := range createTableStmt.Cols {
-		column := model.Column{
-			ColumnName: col.Name.Name.O,
-			Type:       col.Tp.String(),
-			IsNullable: true, // MySQL默认列是可以为NULL的，除非显式声明NOT NULL
+	for i, col := range createTableStmt.Cols {
+		column := parseColumnDef(col)
+		column.OrdinalPosition = i + 1
+		schema.Columns = append(schema.Columns, column)
+	}
+
+	for _, constraint := range createTableStmt.Constraints {
+		addConstraint(&schema, constraint)
+	}
+
+	log.Printf("✅ 成功解析表: %s, 列数: %d, 索引数: %d", schema.Name, len(schema.Columns), len(schema.Indexes))
+	return schema
+}
+
+// parseColumnDef 解析列定义，CREATE TABLE 与 ALTER TABLE ADD/MODIFY/CHANGE COLUMN 共用
+func parseColumnDef(col *ast.ColumnDef) model.Column {
+	column := model.Column{
+		ColumnName: col.Name.Name.O,
+		Type:       col.Tp.String(),
+		IsNullable: true, // MySQL默认列是可以为NULL的，除非显式声明NOT NULL
+	}
+
+	// 提取长度、精度等元数据，与 information_schema 的取值保持一致
+	flen, decimal := int64(col.Tp.GetFlen()), int64(col.Tp.GetDecimal())
+	switch col.Tp.GetType() {
+	case mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString:
+		if flen > 0 {
+			column.CharacterMaxLength = &flen
 		}
+	case mysql.TypeNewDecimal:
+		if flen > 0 {
+			column.NumericPrecision = &flen
+			column.NumericScale = lo.ToPtr(max(decimal, 0))
+		}
+	case mysql.TypeEnum, mysql.TypeSet:
+		column.EnumValues = append([]string(nil), col.Tp.GetElems()...)
+	}
 
-		// 提取列的各种属性
-		for _, option := range col.Options {
-			switch option.Tp {
-			case ast.ColumnOptionComment:
-				// 提取列注释：从ValueExpr的Datum.b字段中读取UTF-8编码的字节数组
-				if option.Expr != nil {
-					// 尝试类型断言为test_driver.ValueExpr
-					if valueExpr, ok := option.Expr.(*test_driver.ValueExpr); ok {
-						// Datum.b 存储的是UTF-8编码的字节数组
-						if len(valueExpr.Datum.GetBytes()) > 0 {
-							column.Comment = string(valueExpr.Datum.GetBytes())
-						}
+	// 提取列的各种属性
+	for _, option := range col.Options {
+		switch option.Tp {
+		case ast.ColumnOptionComment:
+			// 提取列注释：从ValueExpr的Datum.b字段中读取UTF-8编码的字节数组
+			if option.Expr != nil {
+				// 尝试类型断言为test_driver.ValueExpr
+				if valueExpr, ok := option.Expr.(*test_driver.ValueExpr); ok {
+					// Datum.b 存储的是UTF-8编码的字节数组
+					if len(valueExpr.Datum.GetBytes()) > 0 {
+						column.Comment = string(valueExpr.Datum.GetBytes())
 					}
 				}
-				// 如果Expr方式没取到，尝试StrValue（兼容处理）
-				if column.Comment == "" && option.StrValue != "" {
-					column.Comment = option.StrValue
-				}
-			case ast.ColumnOptionDefaultValue:
-				// 提取默认值：需要区分ValueExpr（字符串/数值）和FuncCallExpr（函数如CURRENT_TIMESTAMP）
-				if option.Expr != nil {
-					var defaultVal string
-
-					// 处理ValueExpr类型（字符串或数值默认值）
-					if valueExpr, ok := option.Expr.(*test_driver.ValueExpr); ok {
-						// 如果Datum.b为空字节数组，表示空字符串''
-						if len(valueExpr.Datum.GetBytes()) == 0 {
-							defaultVal = ""
-						} else {
-							// 否则转换字节数组为字符串
-							defaultVal = string(valueExpr.Datum.GetBytes())
-						}
-					} else if funcExpr, ok := option.Expr.(*ast.FuncCallExpr); ok {
-						// 处理FuncCallExpr类型（如CURRENT_TIMESTAMP）
-						defaultVal = funcExpr.FnName.O
-					}
-
-					column.Default = &defaultVal
-				}
-			case ast.ColumnOptionAutoIncrement:
-				// 标记自增列
-				column.IsAutoIncrement = true
-			case ast.ColumnOptionNull:
-				// 标记允许NULL
-				column.IsNullable = true
-			case ast.ColumnOptionNotNull:
-				// 标记不允许NULL
-				column.IsNullable = false
-			case ast.ColumnOptionPrimaryKey:
-				// 标记主键
-				column.IsPrimaryKey = true
-			case ast.ColumnOptionUniqKey:
-				// 标记唯一键
-				column.IsUnique = true
 			}
+			// 如果Expr方式没取到，尝试StrValue（兼容处理）
+			if column.Comment == "" && option.StrValue != "" {
+				column.Comment = option.StrValue
+			}
+		case ast.ColumnOptionDefaultValue:
+			column.Default = parseDefaultValue(option.Expr)
+		case ast.ColumnOptionOnUpdate:
+			// 提取完整的 ON UPDATE 表达式（如 CURRENT_TIMESTAMP、CURRENT_TIMESTAMP(3)）
+			column.OnUpdate = restoreOnUpdate(option.Expr)
+		case ast.ColumnOptionAutoIncrement:
+			// 标记自增列
+			column.IsAutoIncrement = true
+		case ast.ColumnOptionNull:
+			// 标记允许NULL
+			column.IsNullable = true
+		case ast.ColumnOptionNotNull:
+			// 标记不允许NULL
+			column.IsNullable = false
+		case ast.ColumnOptionPrimaryKey:
+			// 标记主键
+			column.IsPrimaryKey = true
+		case ast.ColumnOptionUniqKey:
+			// 标记唯一键
+			column.IsUnique = true
 		}
+	}
+
+	// 提取字符集校对规则
+	if col.Tp.GetCollate() != "" {
+		column.Collate = col.Tp.GetCollate()
+	}
+
+	return column
+}
+
+// parseDefaultValue 提取默认值：需要区分ValueExpr（字符串/数值）和FuncCallExpr（函数如CURRENT_TIMESTAMP）
+func parseDefaultValue(expr ast.ExprNode) *string {
+	if expr == nil {
+		return nil
+	}
+
+	var defaultVal string
 
-		// 提取字符集校对规则
-		if col.Tp.GetCollate() != "" {
-			column.Collate = col.Tp.GetCollate()
+	// 处理ValueExpr类型（字符串或数值默认值）
+	if valueExpr, ok := expr.(*test_driver.ValueExpr); ok {
+		// 如果Datum.b为空字节数组，表示空字符串''
+		if len(valueExpr.Datum.GetBytes()) == 0 {
+			defaultVal = ""
+		} else {
+			// 否则转换字节数组为字符串
+			defaultVal = string(valueExpr.Datum.GetBytes())
 		}
+	} else if funcExpr, ok := expr.(*ast.FuncCallExpr); ok {
+		// 处理FuncCallExpr类型（如CURRENT_TIMESTAMP）
+		defaultVal = funcExpr.FnName.O
+	}
 
-		schema.Columns = append(schema.Columns, column)
-		// 存储列名到索引的映射，避免使用指针（slice 扩容会导致指针失效）
-		columnIndexMap[column.ColumnName] = len(schema.Columns) - 1
+	return &defaultVal
+}
+
+// restoreOnUpdate 还原 ON UPDATE 表达式，保留小数秒精度等参数，与 information_schema 中的写法保持一致
+// NOW()、LOCALTIME 等同义函数统一还原为 CURRENT_TIMESTAMP，没有参数时去掉括号
+// 示例:
+//   - ON UPDATE CURRENT_TIMESTAMP(3) -> CURRENT_TIMESTAMP(3)
+//   - ON UPDATE now() -> CURRENT_TIMESTAMP
+func restoreOnUpdate(expr ast.ExprNode) *string {
+	if expr == nil {
+		return nil
 	}
-	for _, constraint := range createTableStmt.Constraints {
-		switch constraint.Tp {
-		case ast.ConstraintPrimaryKey:
-			// 处理主键
-			var pkColumns []model.Column
-			for _, indexCol := range constraint.Keys {
-				colName := indexCol.Column.Name.O
-				if colIdx, exists := columnIndexMap[colName]; exists {
-					// 通过索引直接修改 schema.Columns 中的列属性
-					schema.Columns[colIdx].IsPrimaryKey = true
-					schema.Columns[colIdx].IsIndexed = true
-					pkColumns = append(pkColumns, schema.Columns[colIdx])
-				}
-			}
-			schema.PrimaryKey = model.Index{
-				IndexName: "PRIMARY",
-				Columns:   pkColumns,
-			}
+	var sb strings.Builder
+	if err := expr.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return nil
+	}
+	onUpdate := strings.TrimSuffix(sb.String(), "()")
+	return &onUpdate
+}
 
-		case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
-			// 处理唯一索引
-			var uniqueColumns []model.Column
-			for _, indexCol := range constraint.Keys {
-				colName := indexCol.Column.Name.O
-				if colIdx, exists := columnIndexMap[colName]; exists {
-					// 通过索引直接修改 schema.Columns 中的列属性
-					schema.Columns[colIdx].IsUnique = true
-					schema.Columns[colIdx].IsIndexed = true
-					uniqueColumns = append(uniqueColumns, schema.Columns[colIdx])
-				}
-			}
-			indexName := constraint.Name
-			if indexName == "" {
-				// 如果没有指定索引名，使用列名组合
-				indexName = "uk_" + strings.Join(lo.Map(uniqueColumns, func(c model.Column, _ int) string {
-					return c.ColumnName
-				}), "_")
+// addConstraint 将主键、唯一索引、普通索引、外键约束添加到表结构上，CREATE TABLE 与 ALTER TABLE ADD 共用
+func addConstraint(schema *model.Schema, constraint *ast.Constraint) {
+	var index model.Index
+	var prefix string
+	switch constraint.Tp {
+	case ast.ConstraintForeignKey:
+		addForeignKey(schema, constraint)
+		return
+	case ast.ConstraintPrimaryKey:
+		// 处理主键
+		index = model.Index{IndexName: "PRIMARY", IsUnique: true}
+	case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+		// 处理唯一索引
+		index = model.Index{IndexName: constraint.Name, IsUnique: true}
+		prefix = "uk_"
+	case ast.ConstraintKey, ast.ConstraintIndex:
+		// 处理普通索引
+		index = model.Index{IndexName: constraint.Name}
+		prefix = "idx_"
+	default:
+		return
+	}
+	if constraint.Option != nil {
+		index.IndexType = constraint.Option.Tp.String()
+	}
+
+	for _, indexCol := range constraint.Keys {
+		// 跳过函数索引的表达式部分
+		if indexCol.Column == nil {
+			continue
+		}
+		colIdx := findColumn(*schema, indexCol.Column.Name.O)
+		if colIdx < 0 {
+			continue
+		}
+
+		// 通过索引直接修改 schema.Columns 中的列属性
+		schema.Columns[colIdx].IsIndexed = true
+		if index.IsUnique {
+			schema.Columns[colIdx].IsUnique = true
+		}
+		if constraint.Tp == ast.ConstraintPrimaryKey {
+			schema.Columns[colIdx].IsPrimaryKey = true
+		}
+
+		part := model.IndexPart{
+			ColumnName: schema.Columns[colIdx].ColumnName,
+			Seq:        len(index.Columns) + 1,
+			Direction:  lo.Ternary(indexCol.Desc, "DESC", "ASC"),
+		}
+		if indexCol.Length > 0 {
+			part.SubPart = lo.ToPtr(int64(indexCol.Length))
+		}
+		index.Columns = append(index.Columns, schema.Columns[colIdx])
+		index.Parts = append(index.Parts, part)
+	}
+
+	if index.IndexName == "" {
+		// 如果没有指定索引名，使用列名组合
+		index.IndexName = prefix + strings.Join(lo.Map(index.Columns, func(c model.Column, _ int) string {
+			return c.ColumnName
+		}), "_")
+	}
+
+	switch constraint.Tp {
+	case ast.ConstraintPrimaryKey:
+		schema.PrimaryKey = index
+	case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+		schema.UniqueIndex = append(schema.UniqueIndex, index)
+	default:
+		schema.Indexes = append(schema.Indexes, index)
+	}
+}
+
+// addForeignKey 添加外键约束
+// 与 MySQL 一致: 未命名的外键命名为 <表名>_ibfk_<序号>；外键列上没有可用的索引时，自动创建一个普通索引
+func addForeignKey(schema *model.Schema, constraint *ast.Constraint) {
+	foreignKey := model.ForeignKey{Name: constraint.Name}
+	for _, key := range constraint.Keys {
+		if key.Column != nil {
+			foreignKey.Columns = append(foreignKey.Columns, key.Column.Name.O)
+		}
+	}
+	if refer := constraint.Refer; refer != nil {
+		foreignKey.ReferencedTable = refer.Table.Name.O
+		for _, part := range refer.IndexPartSpecifications {
+			if part.Column != nil {
+				foreignKey.ReferencedColumns = append(foreignKey.ReferencedColumns, part.Column.Name.O)
 			}
-			schema.UniqueIndex = append(schema.UniqueIndex, model.Index{
-				IndexName: indexName,
-				Columns:   uniqueColumns,
-			})
-
-		case ast.ConstraintKey, ast.ConstraintIndex:
-			// 处理普通索引
-			var indexColumns []model.Column
-			for _, indexCol := range constraint.Keys {
-				colName := indexCol.Column.Name.O
-				if colIdx, exists := columnIndexMap[colName]; exists {
-					// 通过索引直接修改 schema.Columns 中的列属性
-					schema.Columns[colIdx].IsIndexed = true
-					indexColumns = append(indexColumns, schema.Columns[colIdx])
-				}
+		}
+		if refer.OnDelete != nil {
+			foreignKey.OnDelete = refer.OnDelete.ReferOpt.String()
+		}
+		if refer.OnUpdate != nil {
+			foreignKey.OnUpdate = refer.OnUpdate.ReferOpt.String()
+		}
+	}
+	if len(foreignKey.Columns) == 0 {
+		return
+	}
+	if foreignKey.Name == "" {
+		foreignKey.Name = fmt.Sprintf("%s_ibfk_%d", schema.Name, len(schema.ForeignKeys)+1)
+	}
+	schema.ForeignKeys = append(schema.ForeignKeys, foreignKey)
+
+	if !hasIndexPrefix(*schema, foreignKey.Columns) {
+		addConstraint(schema, &ast.Constraint{
+			Tp:   ast.ConstraintIndex,
+			Name: lo.Ternary(constraint.Name != "", constraint.Name, foreignKey.Columns[0]),
+			Keys: constraint.Keys,
+		})
+	}
+}
+
+// delimiterRegexp 匹配 mysql 客户端的 DELIMITER 命令
+var delimiterRegexp = regexp.MustCompile(`(?i)^\s*DELIMITER\s+(\S+)\s*$`)
+
+// parseErrorRegexp 匹配 TiDB parser 错误信息中的行列号
+var parseErrorRegexp = regexp.MustCompile(`line (\d+) column (\d+)`)
+
+// expandDelimiters 处理 DELIMITER 命令
+// DELIMITER 是 mysql 客户端的命令，parser 无法识别。这里将自定义分隔符结尾的语句改写为以分号结尾，
+// 无法解析的语句（如触发器）替换为空行并给出警告。改写时保持行号不变，保证错误位置与原文件一致
+func expandDelimiters(tidbParser *parser.Parser, file sqlFile) string {
+	if !strings.Contains(strings.ToUpper(file.Content), "DELIMITER") {
+		return file.Content
+	}
+
+	var builder strings.Builder
+	var block []string
+	delimiter, blockLine := ";", 0
+	for i, line := range strings.SplitAfter(file.Content, "\n") {
+		if matches := delimiterRegexp.FindStringSubmatch(line); matches != nil && len(block) == 0 {
+			delimiter = matches[1]
+			builder.WriteString(blankLines(line))
+			continue
+		}
+		if delimiter == ";" {
+			builder.WriteString(line)
+			continue
+		}
+
+		if len(block) == 0 {
+			blockLine = i + 1
+		}
+		block = append(block, line)
+		if !strings.HasSuffix(strings.TrimRight(line, " \t\r\n"), delimiter) {
+			continue
+		}
+
+		statement := strings.Join(block, "")
+		idx := strings.LastIndex(statement, delimiter)
+		statement = statement[:idx] + ";" + strings.Repeat(" ", len(delimiter)-1) + statement[idx+len(delimiter):]
+		if _, _, err := tidbParser.ParseSQL(statement); err != nil {
+			log.Printf("⚠️ 跳过无法解析的语句 [%s:%d]，存储过程、触发器等不影响表结构: %v", file.Path, blockLine, err)
+			builder.WriteString(blankLines(statement))
+		} else {
+			builder.WriteString(statement)
+		}
+		block = nil
+	}
+	// 没有以分隔符结尾的语句原样保留，由 parser 报告错误位置
+	builder.WriteString(strings.Join(block, ""))
+	return builder.String()
+}
+
+// blankLines 将文本替换为相同行数的空行
+func blankLines(text string) string {
+	return strings.Repeat("\n", strings.Count(text, "\n"))
+}
+
+// leadingTrivia 计算语句开头的空白和注释长度，使语句位置指向第一个关键字
+func leadingTrivia(text string) int {
+	offset := 0
+	for offset < len(text) {
+		rest := text[offset:]
+		switch {
+		case strings.TrimLeft(rest, " \t\r\n") != rest:
+			offset += len(rest) - len(strings.TrimLeft(rest, " \t\r\n"))
+		case strings.HasPrefix(rest, "--"), strings.HasPrefix(rest, "#"):
+			end := strings.Index(rest, "\n")
+			if end < 0 {
+				return len(text)
 			}
-			indexName := constraint.Name
-			if indexName == "" {
-				// 如果没有指定索引名，使用列名组合
-				indexName = "idx_" + strings.Join(lo.Map(indexColumns, func(c model.Column, _ int) string {
-					return c.ColumnName
-				}), "_")
+			offset += end + 1
+		case strings.HasPrefix(rest, "/*") && !strings.HasPrefix(rest, "/*!"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				return len(text)
 			}
-			schema.Indexes = append(schema.Indexes, model.Index{
-				IndexName: indexName,
-				Columns:   indexColumns,
-			})
+			offset += end + 2
 		default:
-
+			return offset
 		}
 	}
+	return offset
+}
 
-	log.Printf("✅ 成功解析表: %s, 列数: %d, 索引数: %d", schema.Name, len(schema.Columns), len(schema.Indexes))
-	return schema, nil
+// textPosition 将偏移量转换为 文件:行:列 形式的位置
+func textPosition(path, content string, offset int) string {
+	offset = min(offset, len(content))
+	line := strings.Count(content[:offset], "\n") + 1
+	column := offset - strings.LastIndex(content[:offset], "\n")
+	return fmt.Sprintf("%s:%d:%d", path, line, column)
+}
+
+// parseErrorPosition 从 parser 的错误信息中提取 文件:行:列 形式的位置
+func parseErrorPosition(path string, err error) string {
+	matches := parseErrorRegexp.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return path
+	}
+	return fmt.Sprintf("%s:%s:%s", path, matches[1], matches[2])
 }
@@ -5,13 +5,17 @@ import (
 	"log"
 
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/LingoJack/model_infrax/config"
+	"github.com/LingoJack/model_infrax/model"
 	"github.com/LingoJack/model_infrax/tool"
 	"github.com/pingcap/tidb/pkg/parser"
 	"github.com/pingcap/tidb/pkg/parser/ast"
 	_ "github.com/pingcap/tidb/pkg/parser/test_driver"
+	"github.com/samber/lo"
 )
 
 type colX struct {
@@ -149,3 +153,272 @@ func TestDebugAST(t *testing.T) {
 		}
 	}
 }
+
+func TestStatementParserReplayMigrations(t *testing.T) {
+	sqlFilePath := filepath.Join(t.TempDir(), "schema.sql")
+	sql := `
+CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  name varchar(32) NOT NULL COMMENT '用户名',
+  email varchar(64) DEFAULT NULL,
+  legacy int DEFAULT NULL,
+  PRIMARY KEY (id),
+  KEY idx_legacy (legacy)
+) COMMENT='用户表';
+CREATE TABLE t_tmp (id bigint NOT NULL);
+ALTER TABLE t_user ADD COLUMN age int NOT NULL DEFAULT '0' COMMENT '年龄' AFTER name, ADD UNIQUE KEY uk_email (email);
+ALTER TABLE t_user MODIFY COLUMN name varchar(128) NOT NULL COMMENT '昵称';
+ALTER TABLE t_user CHANGE COLUMN email mail varchar(128) NOT NULL;
+ALTER TABLE t_user DROP COLUMN legacy, COMMENT '用户信息表';
+ALTER TABLE t_user RENAME INDEX uk_email TO uk_mail;
+CREATE INDEX idx_age ON t_user (age);
+DROP INDEX idx_age ON t_user;
+CREATE INDEX idx_name_age ON t_user (name(8), age DESC);
+RENAME TABLE t_user TO t_member;
+DROP TABLE IF EXISTS t_tmp, t_not_exists;
+INSERT INTO t_member (name) VALUES ('a');
+`
+	if err := os.WriteFile(sqlFilePath, []byte(sql), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	configger, err := config.NewBuilder().StatementMode(sqlFilePath).AllTables().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	statementParser, err := NewStatementParser(configger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := statementParser.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(schemas) != 1 || schemas[0].Name != "t_member" || schemas[0].Comment != "用户信息表" {
+		t.Fatalf("表回放结果不正确: %s", tool.JsonifyIndent(schemas))
+	}
+	schema := schemas[0]
+
+	columnNames := lo.Map(schema.Columns, func(column model.Column, _ int) string {
+		return column.ColumnName
+	})
+	if strings.Join(columnNames, ",") != "id,name,age,mail" {
+		t.Fatalf("列回放结果不正确: %v", columnNames)
+	}
+	if schema.Columns[2].OrdinalPosition != 3 || schema.Columns[2].Default == nil || *schema.Columns[2].Default != "0" {
+		t.Errorf("ADD COLUMN ... AFTER 回放不正确: %+v", schema.Columns[2])
+	}
+	if schema.Columns[1].Comment != "昵称" || *schema.Columns[1].CharacterMaxLength != 128 {
+		t.Errorf("MODIFY COLUMN 回放不正确: %+v", schema.Columns[1])
+	}
+	if schema.Columns[3].IsNullable || !schema.Columns[3].IsUnique {
+		t.Errorf("CHANGE COLUMN 应保留唯一索引标记: %+v", schema.Columns[3])
+	}
+
+	if len(schema.UniqueIndex) != 1 || schema.UniqueIndex[0].IndexName != "uk_mail" || schema.UniqueIndex[0].Columns[0].ColumnName != "mail" {
+		t.Errorf("唯一索引回放不正确: %s", tool.JsonifyIndent(schema.UniqueIndex))
+	}
+	if len(schema.Indexes) != 1 || schema.Indexes[0].IndexName != "idx_name_age" || len(schema.Indexes[0].Columns) != 2 {
+		t.Errorf("普通索引回放不正确: %s", tool.JsonifyIndent(schema.Indexes))
+	}
+	if schema.Indexes[0].Columns[0].Comment != "昵称" {
+		t.Errorf("索引中的列应为最新定义: %+v", schema.Indexes[0].Columns[0])
+	}
+	if parts := schema.Indexes[0].Parts; len(parts) != 2 || *parts[0].SubPart != 8 || parts[1].Seq != 2 || parts[1].Direction != "DESC" {
+		t.Errorf("索引元数据回放不正确: %s", tool.JsonifyIndent(parts))
+	}
+}
+
+func TestStatementParserReplayErrors(t *testing.T) {
+	for _, sql := range []string{
+		"ALTER TABLE t_missing ADD COLUMN a int",
+		"CREATE TABLE t_a (id int); CREATE TABLE t_a (id int)",
+		"CREATE TABLE t_a (id int); ALTER TABLE t_a DROP COLUMN missing",
+		"CREATE TABLE t_a (id int); DROP INDEX idx_missing ON t_a",
+		"CREATE TABLE t_a (id int); ALTER TABLE t_a DROP FOREIGN KEY fk_missing",
+	} {
+		sqlFilePath := filepath.Join(t.TempDir(), "schema.sql")
+		if err := os.WriteFile(sqlFilePath, []byte(sql), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		configger, err := config.NewBuilder().StatementMode(sqlFilePath).AllTables().Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		statementParser, err := NewStatementParser(configger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = statementParser.Parse(); err == nil {
+			t.Errorf("应返回错误: %s", sql)
+		}
+	}
+}
+
+// newStatementParserForSQL 将SQL写入临时文件并创建解析器
+func newStatementParserForSQL(t *testing.T, sql string) (*StatementParser, string) {
+	t.Helper()
+	sqlFilePath := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(sqlFilePath, []byte(sql), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	configger, err := config.NewBuilder().StatementMode(sqlFilePath).AllTables().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	statementParser, err := NewStatementParser(configger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return statementParser, sqlFilePath
+}
+
+func TestStatementParserWholeFile(t *testing.T) {
+	statementParser, _ := newStatementParserForSQL(t, `
+SET NAMES utf8mb4;
+USE test_db;
+DROP TABLE IF EXISTS t_config;
+CREATE TABLE t_config (
+  id bigint NOT NULL AUTO_INCREMENT,
+  config_key varchar(64) NOT NULL DEFAULT 'a;b' COMMENT '配置键; 唯一',
+  PRIMARY KEY (id)
+) COMMENT='配置表; 全局';
+INSERT INTO t_config (config_key) VALUES ('x;y');
+
+DELIMITER $$
+CREATE TRIGGER trg_config BEFORE INSERT ON t_config FOR EACH ROW
+BEGIN
+  SET NEW.config_key = LOWER(NEW.config_key);
+END$$
+CREATE PROCEDURE p_config() BEGIN SELECT 1; END$$
+DELIMITER ;
+
+ALTER TABLE t_config ADD COLUMN config_value text COMMENT '配置值';
+`)
+
+	schemas, err := statementParser.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 1 || schemas[0].Comment != "配置表; 全局" || len(schemas[0].Columns) != 3 {
+		t.Fatalf("整文件解析结果不正确: %s", tool.JsonifyIndent(schemas))
+	}
+	column := schemas[0].Columns[1]
+	if column.Comment != "配置键; 唯一" || column.Default == nil || *column.Default != "a;b" {
+		t.Errorf("字符串中的分号不应被切分: %+v", column)
+	}
+}
+
+func TestStatementParserErrorPosition(t *testing.T) {
+	statementParser, sqlFilePath := newStatementParserForSQL(t, "CREATE TABLE t_a (\n  id bigint,\n  name varchr(32)\n);\n")
+	_, err := statementParser.Parse()
+	if err == nil || !strings.Contains(err.Error(), sqlFilePath+":3:") {
+		t.Errorf("语法错误应包含文件名和行列号: %v", err)
+	}
+
+	statementParser, sqlFilePath = newStatementParserForSQL(t, "CREATE TABLE t_a (id bigint);\n\n  -- 迁移\n  ALTER TABLE t_b ADD COLUMN name varchar(32);\n")
+	_, err = statementParser.Parse()
+	if err == nil || !strings.Contains(err.Error(), sqlFilePath+":4:3") {
+		t.Errorf("回放错误应包含语句所在的行列号: %v", err)
+	}
+}
+
+func TestStatementParserForeignKeys(t *testing.T) {
+	statementParser, _ := newStatementParserForSQL(t, `
+CREATE TABLE t_user (
+  id bigint NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);
+CREATE TABLE t_order (
+  id bigint NOT NULL AUTO_INCREMENT,
+  user_id bigint NOT NULL,
+  buyer_id bigint,
+  PRIMARY KEY (id),
+  KEY idx_user_id (user_id),
+  CONSTRAINT fk_order_user FOREIGN KEY (user_id) REFERENCES t_user (id) ON DELETE CASCADE
+);
+ALTER TABLE t_order ADD FOREIGN KEY (buyer_id) REFERENCES t_user (id) ON UPDATE SET NULL;
+ALTER TABLE t_order RENAME COLUMN buyer_id TO customer_id;
+RENAME TABLE t_user TO t_member;
+CREATE TABLE t_order_copy LIKE t_order;
+`)
+	schemas, err := statementParser.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := schemas[1]
+	if len(order.ForeignKeys) != 2 {
+		t.Fatalf("t_order 外键解析不正确: %+v", order.ForeignKeys)
+	}
+	if foreignKey := order.ForeignKeys[0]; foreignKey.Name != "fk_order_user" || foreignKey.OnDelete != "CASCADE" || foreignKey.ReferencedTable != "t_member" {
+		t.Errorf("外键信息不正确: %+v", foreignKey)
+	}
+	if foreignKey := order.ForeignKeys[1]; foreignKey.Name != "t_order_ibfk_2" || foreignKey.Columns[0] != "customer_id" || foreignKey.OnUpdate != "SET NULL" {
+		t.Errorf("未命名外键或重命名列后的外键不正确: %+v", foreignKey)
+	}
+	// user_id 已有索引，只需为 buyer_id 自动创建索引
+	indexNames := lo.Map(order.Indexes, func(index model.Index, _ int) string {
+		return index.IndexName
+	})
+	if strings.Join(indexNames, ",") != "idx_user_id,buyer_id" || !order.Columns[2].IsIndexed {
+		t.Errorf("外键自动创建的索引不正确: %v", indexNames)
+	}
+	if copied := schemas[2]; len(copied.ForeignKeys) != 0 {
+		t.Errorf("CREATE TABLE ... LIKE 不应复制外键: %+v", copied.ForeignKeys)
+	}
+}
+
+func TestStatementParserEnumValues(t *testing.T) {
+	statementParser, _ := newStatementParserForSQL(t, `
+CREATE TABLE t_order (
+  id bigint NOT NULL AUTO_INCREMENT,
+  status enum('draft','in-progress','it''s') NOT NULL DEFAULT 'draft',
+  tags set('hot','new') DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+`)
+	schemas, err := statementParser.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	columns := schemas[0].Columns
+	if fmt.Sprint(columns[1].EnumValues) != "[draft in-progress it's]" {
+		t.Errorf("ENUM 取值解析不正确: %q", columns[1].EnumValues)
+	}
+	if fmt.Sprint(columns[2].EnumValues) != "[hot new]" {
+		t.Errorf("SET 取值解析不正确: %q", columns[2].EnumValues)
+	}
+	if len(columns[0].EnumValues) != 0 {
+		t.Errorf("非 ENUM 列不应有取值: %q", columns[0].EnumValues)
+	}
+}
+
+func TestStatementParserOnUpdate(t *testing.T) {
+	statementParser, _ := newStatementParserForSQL(t, `
+CREATE TABLE t_order (
+  id bigint NOT NULL AUTO_INCREMENT,
+  created_at datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  updated_at datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+  modified_at timestamp NULL DEFAULT NULL ON UPDATE now(),
+  PRIMARY KEY (id)
+);
+`)
+	schemas, err := statementParser.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	columns := schemas[0].Columns
+	if columns[1].OnUpdate != nil {
+		t.Errorf("没有 ON UPDATE 的列不应有表达式: %s", *columns[1].OnUpdate)
+	}
+	if columns[2].OnUpdate == nil || *columns[2].OnUpdate != "CURRENT_TIMESTAMP(3)" {
+		t.Errorf("ON UPDATE 表达式应保留小数秒精度: %+v", columns[2].OnUpdate)
+	}
+	if columns[3].OnUpdate == nil || *columns[3].OnUpdate != "CURRENT_TIMESTAMP" {
+		t.Errorf("ON UPDATE now() 应还原为 CURRENT_TIMESTAMP: %+v", columns[3].OnUpdate)
+	}
+}
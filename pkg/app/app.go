@@ -3,11 +3,13 @@ package app
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/LingoJack/model_infrax/config"
 	"github.com/LingoJack/model_infrax/generator"
 	"github.com/LingoJack/model_infrax/model"
 	"github.com/LingoJack/model_infrax/parser"
+	"github.com/samber/lo"
 )
 
 // App 应用程序核心结构体
@@ -79,13 +81,11 @@ func NewAppFromBuilder(builder *config.ConfiggerBuilder) (*App, error) {
 // 这是应用的核心方法，负责协调整个代码生成过程
 //
 // 生成流程包括：
-// 1. 根据配置模式（database/statement）选择合适的解析器
-// 2. 解析数据库表结构或SQL文件
+// 1. 根据配置模式（database/statement/sqlite）选择合适的解析器
+// 2. 解析数据库表结构或SQL文件（只生成工具代码等不依赖表结构的产物时跳过）
 // 3. 根据配置过滤需要处理的表
-// 4. 生成Model实体类代码
-// 5. 生成DTO数据传输对象代码
-// 6. 生成DAO数据访问对象代码
-// 7. 生成Tool工具类代码
+// 4. 按 artifacts 的选择依次生成各类产物（默认生成 Model、DTO、VO、DAO、Tool）
+// 5. 检查生成的代码并写入输出目录
 //
 // 返回:
 //   - error: 执行过程中的错误，nil表示成功完成
@@ -93,6 +93,70 @@ func (a *App) Run() error {
 	var schemas []model.Schema
 	var err error
 
+	// use_framework 指定的模板集必须已注册（内置 gorm、itea-go，或通过 generator.RegisterTemplateSet 注册）
+	if err = a.Generator.CheckFramework(); err != nil {
+		return err
+	}
+
+	// artifacts 中选择的产物必须在模板集中声明
+	if err = a.Generator.CheckArtifacts(); err != nil {
+		return err
+	}
+
+	// 只生成工具代码等不依赖表结构的产物时，不连接数据库、不解析表结构
+	if a.Generator.NeedsSchemas() {
+		schemas, err = a.loadSchemas()
+		if err != nil {
+			return err
+		}
+		if len(schemas) == 0 {
+			return nil
+		}
+	} else {
+		log.Println("⏭️ 选择的产物不依赖表结构，跳过表结构解析")
+	}
+
+	// 按模板集中声明的顺序生成各类产物（默认为 Model、DTO、VO、DAO、Tool），生成方式由产物的 Scope 决定
+	// 模板集没有声明的产物不生成，自定义的产物类型与内置产物走同一条流程
+	artifacts := a.Generator.Artifacts()
+	for _, artifact := range artifacts {
+		name := artifactLogName(artifact.Kind)
+		log.Printf("%s 开始生成 %s 代码...", lo.ValueOr(artifactLogIcons, artifact.Kind, "📄"), name)
+		if err = a.Generator.GenerateArtifact(artifact, schemas); err != nil {
+			return fmt.Errorf("生成%s代码失败: %w", name, err)
+		}
+		log.Printf("✅ %s 代码生成完成", name)
+	}
+
+	// 检查生成的代码能否编译，全部通过后统一写入输出目录，避免留下一半新一半旧的代码
+	err = a.Generator.WriteFiles()
+	if err != nil {
+		return fmt.Errorf("写入生成的代码失败: %w", err)
+	}
+
+	if a.Config.GenerateOption.DryRun || a.Config.GenerateOption.Check {
+		log.Println("🎉 所有代码生成完成（dry-run/check 模式，未写入任何文件）")
+	} else {
+		log.Println("🎉 所有代码生成完成！")
+	}
+	log.Printf("📊 生成统计: %d个表 -> %s", len(schemas), strings.Join(lo.Map(artifacts, func(artifact generator.Artifact, _ int) string {
+		return artifactLogName(artifact.Kind)
+	}), " + "))
+
+	return nil
+}
+
+
+// loadSchemas 按生成模式解析表结构，过滤出需要生成的表，并推导关联关系、类型覆盖和枚举类型
+// 没有需要处理的表时返回空列表
+//
+// 返回:
+//   - []model.Schema: 需要生成代码的表结构
+//   - error: 解析过程中的错误
+func (a *App) loadSchemas() ([]model.Schema, error) {
+	var schemas []model.Schema
+	var err error
+
 	// 根据配置的生成模式选择不同的解析器
 	// 采用延迟初始化策略：只在需要时才创建对应的解析器
 	// 这样可以避免 statement 模式下不必要的数据库连接尝试，提升启动速度
@@ -102,18 +166,18 @@ func (a *App) Run() error {
 		// 适用于可以直接连接数据库的场景，能够获取到最准确的表结构信息
 		log.Println("🚀 开始从数据库解析表结构...")
 
-		// 动态创建 DatabaseParser，只在 database 模式下才会尝试连接数据库
+		// 根据配置的数据库方言动态创建解析器，只在 database 模式下才会尝试连接数据库
 		// 这种设计避免了在不需要数据库连接时进行连接尝试
-		var databaseParser *parser.DatabaseParser
-		databaseParser, err = parser.NewDatabaseParser(a.Config)
+		var databaseParser parser.Parser
+		databaseParser, err = parser.NewDialectParser(a.Config)
 		if err != nil {
-			return fmt.Errorf("初始化数据库解析器失败: %w", err)
+			return nil, fmt.Errorf("初始化数据库解析器失败: %w", err)
 		}
 
 		// 解析数据库表结构
 		schemas, err = databaseParser.Parse()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		log.Printf("✅ 数据库解析完成，共获取到 %d 个表", len(schemas))
 
@@ -131,22 +195,43 @@ func (a *App) Run() error {
 		var statementParser *parser.StatementParser
 		statementParser, err = parser.NewStatementParser(a.Config)
 		if err != nil {
-			return fmt.Errorf("初始化SQL文件解析器失败: %w", err)
+			return nil, fmt.Errorf("初始化SQL文件解析器失败: %w", err)
 		}
 
 		// 解析SQL文件中的表结构定义
 		schemas, err = statementParser.Parse()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		log.Printf("✅ SQL文件解析完成，共获取到 %d 个表", len(schemas))
 
 		// 根据配置文件中的表名过滤规则，筛选需要生成代码的表
 		schemas = statementParser.FilterTables(schemas)
 
+	case "sqlite":
+		// 从SQLite文件解析表结构模式
+		// 适用于本地开发和CI测试，不需要启动任何数据库服务
+		log.Println("🚀 开始从SQLite文件解析表结构...")
+
+		var sqliteParser *parser.SqliteParser
+		sqliteParser, err = parser.NewSqliteParser(a.Config)
+		if err != nil {
+			return nil, fmt.Errorf("初始化SQLite解析器失败: %w", err)
+		}
+
+		// 解析SQLite文件中的表结构
+		schemas, err = sqliteParser.Parse()
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("✅ SQLite文件解析完成，共获取到 %d 个表", len(schemas))
+
+		// 根据配置文件中的表名过滤规则，筛选需要生成代码的表
+		schemas = sqliteParser.FilterTables(schemas)
+
 	default:
 		// 不支持的生成模式，返回明确的错误信息
-		return fmt.Errorf("不支持的生成模式: %s，请使用 'database' 或 'statement'", a.Config.GenerateConfig.GenerateMode)
+		return nil, fmt.Errorf("不支持的生成模式: %s，请使用 'database'、'statement' 或 'sqlite'", a.Config.GenerateConfig.GenerateMode)
 	}
 
 	// 输出过滤后的表数量，方便用户了解处理范围
@@ -155,80 +240,47 @@ func (a *App) Run() error {
 	// 检查是否有表需要处理，如果没有则提前退出
 	if len(schemas) == 0 {
 		log.Println("⚠️ 没有找到需要处理的表，请检查配置文件中的表过滤规则")
-		return nil
+		return nil, nil
 	}
 
-	// 开始生成Model代码
-	// Model是数据实体类，用于表示数据库表结构
-	log.Println("🏗️ 开始生成 Model 代码...")
-
-	// 根据配置决定是生成到一个文件还是分别生成到多个文件
-	// 支持两种模式：
-	// 1. 所有Model生成到一个文件（适合小项目）
-	// 2. 每个Model生成到独立文件（适合大项目，便于维护）
-	if a.Config.GenerateOption.ModelAllInOneFile {
-		err = a.Generator.GenerateModel(schemas, a.Config.GenerateOption.ModelAllInOneFileName)
-	} else {
-		err = a.Generator.GenerateModelOneByOne(schemas)
-	}
-	if err != nil {
-		return fmt.Errorf("生成Model代码失败: %w", err)
+	// 去除表名前缀后，不同的表不能对应同一个结构体名
+	if err = a.Generator.CheckEntityNames(schemas); err != nil {
+		return nil, err
 	}
 
-	log.Println("✅ Model 代码生成完成")
+	// 根据外键推导表之间的关联关系，只有被引用的表也参与生成时才会生成关联字段和预加载方法
+	schemas = a.Generator.ResolveRelations(schemas)
 
-	// 开始生成DTO代码
-	// DTO（Data Transfer Object）是数据传输对象，用于API接口的数据交换
-	// 包含请求和响应的数据结构，以及数据验证和转换逻辑
-	log.Println("📝 开始生成 DTO 代码...")
-
-	// 生成DTO数据传输对象代码，每个表生成对应的DTO结构
-	err = a.Generator.GenerateDTOOneByOne(schemas)
-	if err != nil {
-		return fmt.Errorf("生成DTO代码失败: %w", err)
+	// 按 type_overrides 覆盖列的 Go 类型，如 decimal 使用 decimal.Decimal 避免金额丢失精度
+	if schemas, err = a.Generator.ApplyTypeOverrides(schemas); err != nil {
+		return nil, err
 	}
 
-	log.Println("✅ DTO 代码生成完成")
+	// 为 ENUM、SET 列生成具名类型和常量，已被 type_overrides 覆盖的列除外
+	schemas = a.Generator.ResolveEnumTypes(schemas)
 
-	// 开始生成VO代码
-	// VO（View Object）是视图对象，用于API响应的数据展示
-	// 只包含基本字段，JSON tag 带 omitempty，没有扩展字段和 Builder 模式
-	log.Println("👁️ 开始生成 VO 代码...")
-
-	// 生成VO视图对象代码，每个表生成对应的VO结构
-	err = a.Generator.GenerateVOOneByOne(schemas)
-	if err != nil {
-		return fmt.Errorf("生成VO代码失败: %w", err)
-	}
-
-	log.Println("✅ VO 代码生成完成")
-
-	// 开始生成DAO代码
-	// DAO（Data Access Object）是数据访问对象，提供数据库操作方法
-	// 包含增删改查等基本操作，以及复杂查询方法
-	log.Println("🗄️ 开始生成 DAO 代码...")
-
-	// 生成DAO数据访问对象代码，每个表生成对应的DAO结构
-	err = a.Generator.GenerateDAOOneByOne(schemas)
-	if err != nil {
-		return fmt.Errorf("生成DAO代码失败: %w", err)
-	}
-
-	log.Println("✅ DAO 代码生成完成")
+	return schemas, nil
+}
 
-	// 开始生成Tool工具类代码
-	// Tool提供通用的辅助功能，如字符串处理、数据转换、验证等
-	// 这些工具类可以在整个项目中复用
-	log.Println("🛠️ 开始生成 Tool 工具代码...")
+// artifactLogIcons 内置产物在日志中使用的图标，自定义产物使用 📄
+var artifactLogIcons = map[string]string{
+	generator.ArtifactPo:   "🏗️",
+	generator.ArtifactDto:  "📝",
+	generator.ArtifactVo:   "👁️",
+	generator.ArtifactDao:  "🗄️",
+	generator.ArtifactTool: "🛠️",
+}
 
-	// 生成Tool工具类代码，包括各种通用的辅助方法
-	err = a.Generator.GenerateAllTools()
-	if err != nil {
-		return fmt.Errorf("生成Tool代码失败: %w", err)
+// artifactLogName 返回产物在日志中的名称，内置产物沿用 Model、DTO 等习惯叫法，自定义产物使用产物类型
+func artifactLogName(kind string) string {
+	switch kind {
+	case generator.ArtifactPo:
+		return "Model"
+	case generator.ArtifactTool:
+		return "Tool"
+	case generator.ArtifactDto, generator.ArtifactVo, generator.ArtifactDao:
+		return strings.ToUpper(kind)
+	default:
+		return kind
 	}
-
-	log.Println("🎉 所有代码生成完成！")
-	log.Printf("📊 生成统计: %d个表 -> Model + DTO + VO + DAO + Tools", len(schemas))
-
-	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LingoJack/model_infrax/config"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestRunSqliteMode 端到端测试：从 SQLite 文件生成全部代码，不依赖任何数据库服务
+func TestRunSqliteMode(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+	outputPath := filepath.Join(dir, "output")
+
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("创建SQLite文件失败: %v", err)
+	}
+	for _, statement := range []string{
+		`create table t_user (
+			id integer primary key autoincrement,
+			user_name varchar(64) not null,
+			email text,
+			created_at datetime not null
+		)`,
+		`create unique index uk_user_name on t_user (user_name)`,
+		`create table t_order (id integer primary key, user_id integer not null, amount decimal(10, 2))`,
+	} {
+		if err = db.Exec(statement).Error; err != nil {
+			t.Fatalf("执行建表语句失败: %v", err)
+		}
+	}
+	sqlDB, _ := db.DB()
+	_ = sqlDB.Close()
+
+	app, err := NewAppFromBuilder(
+		config.NewBuilder().
+			SqliteMode(dbPath).
+			Tables("t_user").
+			OutputPath(outputPath).
+			ModulePath("example.com/app/output"),
+	)
+	if err != nil {
+		t.Fatalf("创建应用失败: %v", err)
+	}
+	if err = app.Run(); err != nil {
+		t.Fatalf("生成代码失败: %v", err)
+	}
+
+	for _, file := range []string{
+		"po/t_user.go",
+		"dto/t_user_dto.go",
+		"vo/t_user_vo.go",
+		"dao/t_user_dao.go",
+	} {
+		path := filepath.Join(outputPath, file)
+		if _, err = parser.ParseFile(token.NewFileSet(), path, nil, parser.AllErrors); err != nil {
+			t.Errorf("生成的文件不是合法的 Go 代码 [%s]: %v", file, err)
+		}
+	}
+
+	// 未被选中的表不应生成代码
+	if _, err = os.Stat(filepath.Join(outputPath, "po", "t_order.go")); !os.IsNotExist(err) {
+		t.Errorf("t_order 不应生成代码")
+	}
+
+	po, err := os.ReadFile(filepath.Join(outputPath, "po", "t_user.go"))
+	if err != nil {
+		t.Fatalf("读取生成的 PO 文件失败: %v", err)
+	}
+	for _, expected := range []string{
+		"type TUser struct",
+		"primaryKey;autoIncrement;",
+		"Email     *string",
+		`return "t_user"`,
+	} {
+		if !strings.Contains(string(po), expected) {
+			t.Errorf("生成的 PO 缺少内容: %s\n%s", expected, po)
+		}
+	}
+}
+
+// TestRunStatementModeToolArtifacts 端到端测试：artifacts 只选择工具代码时不解析表结构，SQL 文件不存在也能生成
+func TestRunStatementModeToolArtifacts(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "output")
+	app, err := NewAppFromBuilder(config.NewBuilder().StatementMode(filepath.Join(t.TempDir(), "missing.sql")).AllTables().OutputPath(outputPath).ModulePath("example.com/app/output").Artifacts("tool"))
+	if err != nil {
+		t.Fatalf("创建应用失败: %v", err)
+	}
+	if err = app.Run(); err != nil {
+		t.Fatalf("只生成工具代码时不应解析表结构: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(outputPath, "tool/str.go")); err != nil {
+		t.Errorf("工具代码没有生成: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(outputPath, "po")); !os.IsNotExist(err) {
+		t.Errorf("没有选中的产物不应生成")
+	}
+}